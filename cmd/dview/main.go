@@ -1,18 +1,51 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/dimcz/viewer/internal/config"
+	"github.com/dimcz/viewer/internal/headless"
 	"github.com/dimcz/viewer/internal/viewer"
 	"github.com/dimcz/viewer/pkg/docker"
+	"github.com/dimcz/viewer/pkg/journal"
+	"github.com/dimcz/viewer/pkg/kube"
+	"github.com/dimcz/viewer/pkg/listener"
 	"github.com/dimcz/viewer/pkg/logger"
+	"github.com/dimcz/viewer/pkg/replay"
+	"github.com/dimcz/viewer/pkg/snapshot"
+	"github.com/dimcz/viewer/pkg/source"
+	"golang.org/x/term"
 )
 
 const VERSION = "0.0.7"
 
 func main() {
-	cfg := config.Init()
+	if len(os.Args) > 1 && os.Args[1] == metadataCommand {
+		if err := printPluginMetadata(); err != nil {
+			fmt.Println(err)
+		}
+
+		return
+	}
+
+	cfg, err := config.Init()
+	if err != nil {
+		fmt.Println(err)
+
+		return
+	}
+
+	if cfg.Command == config.CommandConfigDump {
+		if err := cfg.Dump(os.Stdout); err != nil {
+			fmt.Println(err)
+		}
+
+		return
+	}
 
 	log := logger.Init(cfg.LogFile)
 	defer log.Close()
@@ -25,16 +58,129 @@ func main() {
 		return
 	}
 
-	client, err := docker.Client(log, cfg)
+	var sources []source.LogSource
+
+	if cfg.Open != "" {
+		snap, err := snapshot.NewSource(log, cfg.Open)
+		if err != nil {
+			fmt.Println(err)
+
+			return
+		}
+
+		defer snap.Close()
+
+		sources = append(sources, snap)
+	} else if cfg.Replay == "" {
+		client, err := docker.Client(log, cfg)
+
+		switch {
+		case err == nil:
+			defer client.Close()
+
+			sources = append(sources, client)
+		case !cfg.OtherSources():
+			fmt.Println(err)
+
+			return
+		default:
+			log.Error("docker is not available: ", err)
+		}
+	}
+
+	if cfg.Kube {
+		k, err := kubeSource(log, cfg)
+		if err != nil {
+			fmt.Println(err)
+
+			return
+		}
+
+		sources = append(sources, k)
+	}
+
+	if cfg.Journal != "" {
+		j, err := journal.New(log, cfg.Journal, cfg.Tail)
+		if err != nil {
+			fmt.Println(err)
+
+			return
+		}
+
+		defer j.Close()
+
+		sources = append(sources, j)
+	}
+
+	if cfg.Replay != "" {
+		r, err := replay.New(log, cfg.Replay, cfg.ReplaySpeed)
+		if err != nil {
+			fmt.Println(err)
+
+			return
+		}
+
+		sources = append(sources, r)
+	}
+
+	if len(cfg.Listen) > 0 {
+		l, err := listener.New(log, cfg.Listen, cfg.ListenSplit)
+		if err != nil {
+			fmt.Println(err)
+
+			return
+		}
+
+		defer l.Close()
+
+		sources = append(sources, l)
+	}
+
+	if len(cfg.Files) > 0 {
+		sources = append(sources, source.NewFiles(log, cfg.Files))
+	}
+
+	if len(cfg.Commands) > 0 {
+		sources = append(sources, source.NewCommands(log, cfg.Commands))
+	}
+
+	nav, err := source.NewNavigator(log, sources...)
 	if err != nil {
 		fmt.Println(err)
 
 		return
 	}
 
-	defer client.Close()
+	// Without a terminal the logs are streamed like dview logs --follow.
+	if cfg.Command == "" && !term.IsTerminal(int(os.Stdout.Fd())) {
+		cfg.Command, cfg.Follow = config.CommandLogs, true
+	}
+
+	if cfg.Command != "" {
+		runHeadless(cfg, nav)
 
-	v, err := viewer.Init(log, cfg, client)
+		return
+	}
+
+	run(log, cfg, nav)
+}
+
+func kubeSource(log *logger.Logger, cfg *config.Config) (*kube.Kube, error) {
+	fileName := cfg.KubeConfig
+	if fileName == "" {
+		fileName = kube.DefaultConfigPath()
+	}
+
+	kc, err := kube.LoadConfig(fileName, cfg.KubeContext)
+	if err != nil {
+		return nil, err
+	}
+
+	return kube.New(log, kc, cfg.KubeNamespace, cfg.KubeSelector, cfg.Timestamp), nil
+}
+
+func run(log *logger.Logger, cfg *config.Config, nav *source.Navigator) {
+	v, err := viewer.Init(log, cfg, nav)
 	if err != nil {
 		fmt.Println(err)
 
@@ -47,3 +193,12 @@ func main() {
 		fmt.Println(err)
 	}
 }
+
+func runHeadless(cfg *config.Config, nav *source.Navigator) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := headless.New(cfg, nav, os.Stdout).Run(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// metadataCommand is called by the docker CLI to discover the plugin.
+const metadataCommand = "docker-cli-plugin-metadata"
+
+type pluginMetadata struct {
+	SchemaVersion    string `json:"SchemaVersion"`
+	Vendor           string `json:"Vendor"`
+	Version          string `json:"Version"`
+	ShortDescription string `json:"ShortDescription"`
+	URL              string `json:"URL"`
+}
+
+func printPluginMetadata() error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(pluginMetadata{
+		SchemaVersion:    "0.1.0",
+		Vendor:           "dimcz",
+		Version:          VERSION,
+		ShortDescription: "Interactive viewer of container logs",
+		URL:              "https://github.com/dimcz/dview",
+	})
+}
@@ -1,26 +1,260 @@
 package config
 
-import "github.com/spf13/pflag"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dimcz/viewer/pkg/journal"
+	"github.com/dimcz/viewer/pkg/listener"
+	"github.com/dimcz/viewer/pkg/oviewer"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// PluginName is the docker CLI command of the plugin: docker dview.
+	PluginName = "dview"
+
+	pluginEnv = "DOCKER_CLI_PLUGIN_ORIGINAL_CLI_COMMAND"
+)
+
+// Headless subcommands, dview without one starts the viewer.
+const (
+	CommandLs   = "ls"
+	CommandLogs = "logs"
+	CommandGrep = "grep"
+
+	// CommandConfigDump prints the effective configuration.
+	CommandConfigDump = "config dump"
+)
 
 type Config struct {
+	ConfigFile string
+	Rules      []Rule
+	Keybind    map[string][]string
+	Viewer     oviewer.Config
+
 	Version   bool
+	Fresh     bool
 	LogFile   string
 	Tail      int
 	Timestamp bool
+	Open      string
+	Files     []string
+	Commands  []string
+	Journal   string
+
+	Replay      string
+	ReplaySpeed float64
+
+	Listen      []string
+	ListenSplit string
+
+	Kube          bool
+	KubeConfig    string
+	KubeContext   string
+	KubeNamespace string
+	KubeSelector  string
+
+	Record         string
+	RecordSize     string
+	RecordInterval time.Duration
+	RecordCompress string
+
+	DockerHost    string
+	DockerContext string
+	DockerConfig  string
+	Containers    []string
+
+	Command       string
+	Pattern       string
+	TailSet       bool
+	JSON          bool
+	Follow        bool
+	Since         string
+	Until         string
+	NoPrefix      bool
+	CaseSensitive bool
+	Regexp        bool
 }
 
-func Init() *Config {
-	var config Config
+func Init() (*Config, error) {
+	config := Config{
+		Keybind: DefaultKeybind(),
+		Viewer:  DefaultViewer(),
+	}
 
+	pflag.StringVar(&(config.ConfigFile),
+		"config", "", "Configuration file (default "+DefaultFile()+")")
 	pflag.BoolVarP(&(config.Version),
 		"version", "v", false, "Print version information")
+	pflag.BoolVar(&(config.Fresh),
+		"fresh", false, "Start without the saved state of the containers")
 	pflag.BoolVarP(&(config.Timestamp),
 		"timestamps", "t", false, "Show timestamps")
 	pflag.StringVarP(&(config.LogFile),
 		"log", "l", "", "Send log messages to file")
 	pflag.IntVarP(&(config.Tail),
 		"tail", "n", 1_000, "Number of lines to show from the end of the logs")
-	pflag.Parse()
+	pflag.StringVar(&(config.Open),
+		"open", "", "Open a session snapshot instead of the docker daemon")
+	pflag.StringArrayVarP(&(config.Files),
+		"file", "f", nil, "Follow local log files matching the glob (repeatable)")
+	pflag.StringArrayVar(&(config.Commands),
+		"command", nil, "Show the output of the shell command (repeatable)")
+	pflag.StringVar(&(config.Journal),
+		"journal", "", "Read a journal export or JSON file, or the live journal when no file is given")
+	pflag.Lookup("journal").NoOptDefVal = journal.Follow
+	pflag.StringVar(&(config.Replay),
+		"replay", "", "Replay a file of timestamped lines with the original timing")
+	pflag.Float64Var(&(config.ReplaySpeed),
+		"replay-speed", 1, "Speed factor of the replay (e.g. 10)")
+	pflag.StringArrayVar(&(config.Listen),
+		"listen", nil, "Receive syslog or raw lines on udp://host:port or tcp://host:port (repeatable)")
+	pflag.StringVar(&(config.ListenSplit),
+		"listen-split", listener.SplitHost, "Split received messages by hostname or app-name")
+	pflag.BoolVar(&(config.Kube),
+		"kube", false, "Show the logs of Kubernetes pods")
+	pflag.StringVar(&(config.KubeConfig),
+		"kubeconfig", "", "Path to the kubeconfig file (default $KUBECONFIG or ~/.kube/config)")
+	pflag.StringVar(&(config.KubeContext),
+		"kube-context", "", "Kubeconfig context to use (default the current context)")
+	pflag.StringVar(&(config.KubeNamespace),
+		"namespace", "", "Kubernetes namespace of the pods (default the context namespace)")
+	pflag.StringVar(&(config.KubeSelector),
+		"selector", "", "Kubernetes label selector of the pods (e.g. app=web)")
+	pflag.StringVar(&(config.Record),
+		"record", "", "Record the streams of attached containers to the directory (implies --timestamps)")
+	pflag.StringVar(&(config.RecordSize),
+		"record-size", "", "Rotate a record file when it reaches the size (e.g. 100M)")
+	pflag.DurationVar(&(config.RecordInterval),
+		"record-interval", 0, "Rotate a record file after the interval (e.g. 1h)")
+	pflag.StringVar(&(config.RecordCompress),
+		"record-compress", "", "Compress rotated record files (gz, zst or xz)")
+	pflag.StringVarP(&(config.DockerHost),
+		"host", "H", "", "Docker daemon socket to connect to")
+	pflag.StringVar(&(config.DockerContext),
+		"context", "", "Docker context to use (default the current context)")
+	pflag.BoolVar(&(config.JSON),
+		"json", false, "Print the ls output as JSON")
+	pflag.BoolVar(&(config.Follow),
+		"follow", false, "Keep streaming new lines with logs and grep")
+	pflag.StringVar(&(config.Since),
+		"since", "", "Show lines since the time (RFC3339) or duration (e.g. 15m) with logs and grep")
+	pflag.StringVar(&(config.Until),
+		"until", "", "Show lines until the time (RFC3339) or duration (e.g. 5m) with logs and grep")
+	pflag.BoolVar(&(config.NoPrefix),
+		"no-prefix", false, "Do not prefix lines with the container name")
+	pflag.BoolVar(&(config.CaseSensitive),
+		"case-sensitive", false, "Match the grep pattern case-sensitively")
+	pflag.BoolVar(&(config.Regexp),
+		"regexp", false, "Treat the grep pattern as a regular expression")
+
+	args := os.Args[1:]
+	if IsPlugin() {
+		args = config.parsePluginArgs(args)
+	}
+
+	// Errors exit like pflag.Parse does.
+	_ = pflag.CommandLine.Parse(args)
+
+	if err := applyEnv(pflag.CommandLine, os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	fileName, required := config.ConfigFile, true
+	if fileName == "" {
+		fileName, required = DefaultFile(), false
+	}
+
+	if err := config.load(pflag.CommandLine, fileName, required); err != nil {
+		return nil, errors.Wrap(err, "failed to load configuration")
+	}
+
+	config.TailSet = pflag.CommandLine.Changed("tail")
+
+	// The recorder skips the lines it recorded on an earlier attach by their
+	// timestamps, so the tail sent on every attach is recorded once.
+	if config.Record != "" {
+		config.Timestamp = true
+	}
+	config.Containers = config.parseCommand(pflag.Args())
+
+	return &config, nil
+}
+
+// parseCommand takes the subcommand and the grep pattern
+// from the arguments and returns the remaining containers.
+func (c *Config) parseCommand(args []string) []string {
+	if len(args) == 0 {
+		return args
+	}
+
+	switch args[0] {
+	case CommandLs, CommandLogs:
+		c.Command = args[0]
+
+		return args[1:]
+	case "config":
+		if len(args) > 1 && args[1] == "dump" {
+			c.Command = CommandConfigDump
+
+			return args[2:]
+		}
+	case CommandGrep:
+		c.Command = args[0]
+		if len(args) > 1 {
+			c.Pattern = args[1]
+
+			return args[2:]
+		}
+
+		return nil
+	}
+
+	return args
+}
+
+// IsPlugin reports whether dview runs as the docker CLI plugin.
+func IsPlugin() bool {
+	return os.Getenv(pluginEnv) != "" || strings.HasPrefix(filepath.Base(os.Args[0]), "docker-")
+}
+
+// parsePluginArgs takes the global options the docker CLI passes
+// before the plugin name and returns the arguments of the plugin.
+func (c *Config) parsePluginArgs(args []string) []string {
+	fs := pflag.NewFlagSet("docker", pflag.ContinueOnError)
+	fs.SetInterspersed(false)
+	fs.Usage = func() {}
+
+	fs.StringVarP(&(c.DockerHost), "host", "H", "", "")
+	fs.StringVarP(&(c.DockerContext), "context", "c", "", "")
+	fs.StringVar(&(c.DockerConfig), "config", "", "")
+	fs.BoolP("debug", "D", false, "")
+	fs.StringP("log-level", "l", "", "")
+	fs.Bool("tls", false, "")
+	fs.Bool("tlsverify", false, "")
+	fs.String("tlscacert", "", "")
+	fs.String("tlscert", "", "")
+	fs.String("tlskey", "", "")
+
+	if err := fs.Parse(args); err != nil {
+		return args
+	}
+
+	rest := fs.Args()
+	if len(rest) > 0 && rest[0] == PluginName {
+		return rest[1:]
+	}
+
+	return rest
+}
 
-	return &config
+// OtherSources reports whether sources besides docker are given,
+// so the session is useful without the docker daemon.
+func (c *Config) OtherSources() bool {
+	return len(c.Files) > 0 || len(c.Commands) > 0 || len(c.Listen) > 0 ||
+		c.Journal != "" || c.Replay != "" || c.Kube
 }
@@ -0,0 +1,377 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/dimcz/viewer/pkg/oviewer"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	envPrefix = "DVIEW_"
+
+	keyFilters = "filters"
+	keyKeybind = "keybind"
+	keyRules   = "rules"
+	keyViewer  = "viewer"
+)
+
+// notInFile are the flags which make no sense in the configuration file.
+var notInFile = map[string]bool{"version": true, "config": true}
+
+// DefaultKeybind returns the keys of the dview actions.
+func DefaultKeybind() map[string][]string {
+	return map[string][]string{
+		"prevContainer":  {"left"},
+		"nextContainer":  {"right"},
+		"systemReport":   {"s"},
+		"allLogs":        {"ctrl+y"},
+		"topPanel":       {"T"},
+		"diffPanel":      {"D"},
+		"exportSession":  {"E"},
+		"replayPause":    {"."},
+		"replayForward":  {"]"},
+		"replayBackward": {"["},
+		"replayFaster":   {"+"},
+		"replaySlower":   {"-"},
+	}
+}
+
+// DefaultViewer returns the viewer configuration dview starts with.
+func DefaultViewer() oviewer.Config {
+	c := oviewer.NewConfig()
+	c.General.FollowMode = true
+	c.General.WrapMode = true
+
+	return c
+}
+
+// DefaultFile returns ~/.config/dview/config.yaml,
+// honouring XDG_CONFIG_HOME.
+func DefaultFile() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+
+		dir = filepath.Join(home, ".config")
+	}
+
+	return filepath.Join(dir, PluginName, "config.yaml")
+}
+
+// load reads the configuration file. Flag values from the command
+// line or DVIEW_* variables take precedence over the file.
+func (c *Config) load(fs *pflag.FlagSet, fileName string, required bool) error {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		if os.IsNotExist(err) && !required {
+			return nil
+		}
+
+		return err
+	}
+
+	var file map[string]interface{}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return errors.Wrap(err, fileName)
+	}
+
+	if err := c.apply(fs, file); err != nil {
+		return errors.Wrap(err, fileName)
+	}
+
+	return errors.Wrap(c.checkRules(), fileName)
+}
+
+func (c *Config) apply(fs *pflag.FlagSet, file map[string]interface{}) error {
+	for key, value := range file {
+		switch key {
+		case keyFilters:
+			filters, err := filterRules(value)
+			if err != nil {
+				return errors.Wrap(err, key)
+			}
+
+			c.Viewer.General.Filters = filters
+		case keyKeybind:
+			if err := c.applyKeybind(value); err != nil {
+				return errors.Wrap(err, key)
+			}
+		case keyRules:
+			if err := c.applyRules(value); err != nil {
+				return errors.Wrap(err, key)
+			}
+		case keyViewer:
+			// encoding/json matches the field names case-insensitively,
+			// so the file may use the keys of ov, e.g. StyleBody or general.
+			data, err := json.Marshal(value)
+			if err != nil {
+				return errors.Wrap(err, key)
+			}
+
+			if err := json.Unmarshal(data, &c.Viewer); err != nil {
+				return errors.Wrap(err, key)
+			}
+		default:
+			if err := setFlag(fs, key, value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// applyKeybind splits the bindings between the dview actions
+// and the actions of the viewer.
+func (c *Config) applyKeybind(value interface{}) error {
+	binds, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected a map of actions, got %T", value)
+	}
+
+	for name, v := range binds {
+		keys, err := stringList(v)
+		if err != nil {
+			return errors.Wrap(err, name)
+		}
+
+		if _, ok := c.Keybind[name]; ok {
+			c.Keybind[name] = keys
+
+			continue
+		}
+
+		if c.Viewer.Keybind == nil {
+			c.Viewer.Keybind = make(map[string][]string)
+		}
+
+		c.Viewer.Keybind[name] = keys
+	}
+
+	return nil
+}
+
+// filterRules returns the default filter stack of the documents.
+// A rule is written as in the filter input, e.g. "!health",
+// or as a map with the fields of oviewer.FilterRule.
+func filterRules(value interface{}) ([]oviewer.FilterRule, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		items = []interface{}{value}
+	}
+
+	rules := make([]oviewer.FilterRule, 0, len(items))
+
+	for _, item := range items {
+		if _, ok := item.(map[string]interface{}); !ok {
+			s, err := scalar(item)
+			if err != nil {
+				return nil, err
+			}
+
+			rules = append(rules, oviewer.ParseFilterRule(s))
+
+			continue
+		}
+
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+
+		var rule oviewer.FilterRule
+		if err := json.Unmarshal(data, &rule); err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+func setFlag(fs *pflag.FlagSet, name string, value interface{}) error {
+	f := fs.Lookup(name)
+	if f == nil || notInFile[name] {
+		return fmt.Errorf("unknown option %q", name)
+	}
+
+	if f.Changed {
+		return nil
+	}
+
+	values, err := stringList(value)
+	if err != nil {
+		return errors.Wrap(err, name)
+	}
+
+	for _, v := range values {
+		if err := fs.Set(name, v); err != nil {
+			return errors.Wrap(err, name)
+		}
+	}
+
+	return nil
+}
+
+// applyEnv sets the flags which are not given on the command line
+// from DVIEW_<FLAG> variables, e.g. DVIEW_TAIL or DVIEW_RECORD_SIZE.
+func applyEnv(fs *pflag.FlagSet, lookup func(string) (string, bool)) error {
+	var err error
+
+	fs.VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed {
+			return
+		}
+
+		value, ok := lookup(EnvName(f.Name))
+		if !ok {
+			return
+		}
+
+		if e := fs.Set(f.Name, value); e != nil {
+			err = errors.Wrap(e, EnvName(f.Name))
+		}
+	})
+
+	return err
+}
+
+// EnvName returns the environment variable of the flag.
+func EnvName(flag string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+func stringList(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		list := make([]string, 0, len(v))
+
+		for _, item := range v {
+			s, err := scalar(item)
+			if err != nil {
+				return nil, err
+			}
+
+			list = append(list, s)
+		}
+
+		return list, nil
+	default:
+		s, err := scalar(v)
+		if err != nil {
+			return nil, err
+		}
+
+		return []string{s}, nil
+	}
+}
+
+func scalar(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case int, float64, bool:
+		return fmt.Sprint(v), nil
+	default:
+		return "", fmt.Errorf("expected a value, got %T", value)
+	}
+}
+
+// Dump writes the effective configuration in the format of the file.
+func (c *Config) Dump(w io.Writer) error {
+	return c.dump(w, pflag.CommandLine)
+}
+
+func (c *Config) dump(w io.Writer, fs *pflag.FlagSet) error {
+	out := make(map[string]interface{})
+
+	fs.VisitAll(func(f *pflag.Flag) {
+		if notInFile[f.Name] {
+			return
+		}
+
+		out[f.Name] = flagValue(fs, f)
+	})
+
+	keybind := oviewer.GetKeyBinds(c.Viewer.Keybind)
+	for name, keys := range c.Keybind {
+		keybind[name] = keys
+	}
+
+	viewer := c.Viewer
+	viewer.Keybind = nil
+
+	data, err := json.Marshal(viewer)
+	if err != nil {
+		return err
+	}
+
+	var v map[string]interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	delete(v, "Keybind")
+
+	// The filter stack of general is written under its own key.
+	if g, ok := v["General"].(map[string]interface{}); ok {
+		delete(g, "Filters")
+	}
+
+	out[keyFilters] = c.Viewer.General.Filters
+	out[keyKeybind] = keybind
+	out[keyRules] = c.Rules
+	out[keyViewer] = v
+
+	var b bytes.Buffer
+
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(out); err != nil {
+		return err
+	}
+
+	_, err = w.Write(b.Bytes())
+
+	return err
+}
+
+func flagValue(fs *pflag.FlagSet, f *pflag.Flag) interface{} {
+	switch f.Value.Type() {
+	case "stringArray":
+		v, _ := fs.GetStringArray(f.Name)
+
+		return v
+	case "bool":
+		v, _ := strconv.ParseBool(f.Value.String())
+
+		return v
+	case "int":
+		v, _ := strconv.Atoi(f.Value.String())
+
+		return v
+	case "float64":
+		v, _ := strconv.ParseFloat(f.Value.String(), 64)
+
+		return v
+	default:
+		return f.Value.String()
+	}
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dimcz/viewer/pkg/oviewer"
+	"github.com/spf13/pflag"
+)
+
+func testFlags(c *Config) *pflag.FlagSet {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.IntVarP(&(c.Tail), "tail", "n", 1_000, "")
+	fs.StringArrayVarP(&(c.Files), "file", "f", nil, "")
+	fs.StringVar(&(c.RecordSize), "record-size", "", "")
+
+	return fs
+}
+
+func TestConfig_load(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "config.yaml")
+	data := `
+tail: 50
+file: [/var/log/a.log, /var/log/b.log]
+record-size: 10M
+filters:
+  - web
+  - "!health"
+  - pattern: DEBUG
+    exclude: true
+    context: 2
+keybind:
+  nextContainer: [n]
+  exit: [x]
+viewer:
+  StyleMarkLine:
+    Background: red
+  general:
+    TabWidth: 4
+  highlights:
+    - pattern: ERROR
+      style:
+        foreground: red
+  Mode:
+    json:
+      WrapMode: false
+    quiet:
+      Filters:
+        - pattern: health
+          exclude: true
+        - pattern: DEBUG
+          exclude: true
+          context: 2
+`
+	if err := os.WriteFile(fileName, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Config{Keybind: DefaultKeybind(), Viewer: DefaultViewer()}
+	fs := testFlags(c)
+
+	if err := fs.Parse([]string{"--tail", "10"}); err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{"DVIEW_RECORD_SIZE": "1G", "DVIEW_TAIL": "20"}
+	if err := applyEnv(fs, func(k string) (string, bool) { v, ok := env[k]; return v, ok }); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.load(fs, fileName, true); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Tail != 10 {
+		t.Errorf("Tail = %d, want the flag value 10", c.Tail)
+	}
+
+	if c.RecordSize != "1G" {
+		t.Errorf("RecordSize = %q, want the environment value 1G", c.RecordSize)
+	}
+
+	if want := []string{"/var/log/a.log", "/var/log/b.log"}; !reflect.DeepEqual(c.Files, want) {
+		t.Errorf("Files = %v, want %v", c.Files, want)
+	}
+
+	wantFilters := []oviewer.FilterRule{
+		{Pattern: "web"},
+		{Pattern: "health", Exclude: true},
+		{Pattern: "DEBUG", Exclude: true, Context: 2},
+	}
+	if !reflect.DeepEqual(c.Viewer.General.Filters, wantFilters) {
+		t.Errorf("Viewer.General.Filters = %+v, want %+v", c.Viewer.General.Filters, wantFilters)
+	}
+
+	if want := []string{"n"}; !reflect.DeepEqual(c.Keybind["nextContainer"], want) {
+		t.Errorf("Keybind[nextContainer] = %v, want %v", c.Keybind["nextContainer"], want)
+	}
+
+	if want := []string{"x"}; !reflect.DeepEqual(c.Viewer.Keybind["exit"], want) {
+		t.Errorf("Viewer.Keybind[exit] = %v, want %v", c.Viewer.Keybind["exit"], want)
+	}
+
+	if c.Viewer.StyleMarkLine.Background != "red" || c.Viewer.General.TabWidth != 4 {
+		t.Errorf("Viewer = %+v, want the styles and general of the file", c.Viewer)
+	}
+
+	if !c.Viewer.General.FollowMode {
+		t.Error("Viewer.General.FollowMode was reset by the file")
+	}
+
+	if _, ok := c.Viewer.Mode["json"]; !ok {
+		t.Error("Viewer.Mode has no json mode")
+	}
+
+	if h := c.Viewer.Highlights; len(h) != 1 || h[0].Pattern != "ERROR" || h[0].Style.Foreground != "red" {
+		t.Errorf("Viewer.Highlights = %+v, want the highlight rules of the file", h)
+	}
+
+	if f := c.Viewer.Mode["quiet"].Filters; len(f) != 2 || !f[1].Exclude || f[1].Context != 2 {
+		t.Errorf("Viewer.Mode[quiet].Filters = %+v, want the filter stack of the file", f)
+	}
+
+	var b bytes.Buffer
+	if err := c.dump(&b, fs); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"tail: 10", "record-size: 1G", "nextContainer:", "TabWidth: 4", "pattern: web"} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("dump has no %q:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestConfig_loadUnknown(t *testing.T) {
+	c := &Config{Keybind: DefaultKeybind(), Viewer: DefaultViewer()}
+
+	if err := c.apply(testFlags(c), map[string]interface{}{"tails": 5}); err == nil {
+		t.Error("apply() expected an error for an unknown option")
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/dimcz/viewer/pkg/source"
+	"gopkg.in/yaml.v3"
+)
+
+// Rule selects the view mode of the targets it matches.
+// Image and Name are glob patterns, Label is key or key=value.
+// All given fields have to match.
+type Rule struct {
+	Image string `yaml:"image,omitempty"`
+	Name  string `yaml:"name,omitempty"`
+	Label string `yaml:"label,omitempty"`
+	Mode  string `yaml:"mode"`
+}
+
+// Match reports whether the rule selects the target.
+func (r Rule) Match(t source.Target) bool {
+	if r.Image == "" && r.Name == "" && r.Label == "" {
+		return false
+	}
+
+	if r.Image != "" && !glob(r.Image, t.Image) {
+		return false
+	}
+
+	if r.Name != "" && !glob(r.Name, strings.TrimPrefix(t.Name, "/")) {
+		return false
+	}
+
+	if r.Label != "" {
+		key, value, hasValue := strings.Cut(r.Label, "=")
+
+		v, ok := t.Labels[key]
+		if !ok || (hasValue && !glob(value, v)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ModeFor returns the mode of the first rule matching the target.
+func (c *Config) ModeFor(t source.Target) string {
+	for _, r := range c.Rules {
+		if r.Match(t) {
+			return r.Mode
+		}
+	}
+
+	return ""
+}
+
+func (c *Config) applyRules(value interface{}) error {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return yaml.Unmarshal(data, &c.Rules)
+}
+
+// checkRules reports rules with modes the viewer does not know.
+func (c *Config) checkRules() error {
+	for i, r := range c.Rules {
+		if r.Mode == "" {
+			return fmt.Errorf("rule %d has no mode", i+1)
+		}
+
+		if _, ok := c.Viewer.Mode[r.Mode]; !ok && r.Mode != "general" {
+			return fmt.Errorf("rule %d: %s mode not found", i+1, r.Mode)
+		}
+	}
+
+	return nil
+}
+
+func glob(pattern, s string) bool {
+	ok, err := path.Match(pattern, s)
+
+	return err == nil && ok
+}
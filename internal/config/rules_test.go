@@ -0,0 +1,46 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/dimcz/viewer/pkg/source"
+)
+
+func TestConfig_ModeFor(t *testing.T) {
+	c := &Config{Rules: []Rule{
+		{Image: "nginx*", Mode: "columns"},
+		{Label: "lang=java*", Mode: "java"},
+		{Name: "*-worker", Label: "tier", Mode: "worker"},
+	}}
+
+	tests := []struct {
+		target source.Target
+		want   string
+	}{
+		{source.Target{Name: "/web", Image: "nginx:1.21"}, "columns"},
+		{source.Target{Name: "/api", Image: "api:latest", Labels: map[string]string{"lang": "java17"}}, "java"},
+		{source.Target{Name: "/mail-worker", Labels: map[string]string{"tier": "back"}}, "worker"},
+		{source.Target{Name: "/mail-worker"}, ""},
+		{source.Target{Name: "/db", Image: "postgres"}, ""},
+	}
+
+	for _, tt := range tests {
+		if got := c.ModeFor(tt.target); got != tt.want {
+			t.Errorf("ModeFor(%s) = %q, want %q", tt.target.Name, got, tt.want)
+		}
+	}
+}
+
+func TestConfig_checkRules(t *testing.T) {
+	c := &Config{Viewer: DefaultViewer(), Rules: []Rule{{Image: "nginx*", Mode: "columns"}}}
+
+	if err := c.checkRules(); err == nil {
+		t.Error("checkRules() expected an error for an unknown mode")
+	}
+
+	c.Rules[0].Mode = "general"
+
+	if err := c.checkRules(); err != nil {
+		t.Errorf("checkRules() error = %v", err)
+	}
+}
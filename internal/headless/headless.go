@@ -0,0 +1,335 @@
+package headless
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dimcz/viewer/internal/config"
+	"github.com/dimcz/viewer/pkg/oviewer"
+	"github.com/dimcz/viewer/pkg/source"
+	"github.com/dimcz/viewer/pkg/timestamp"
+	"github.com/pkg/errors"
+)
+
+var ErrNoTargets = errors.New("no containers or files to show")
+
+// Headless writes logs to a plain output instead of the terminal pager.
+type Headless struct {
+	cfg *config.Config
+	nav *source.Navigator
+	out io.Writer
+
+	mu sync.Mutex
+}
+
+func New(cfg *config.Config, nav *source.Navigator, out io.Writer) *Headless {
+	return &Headless{cfg: cfg, nav: nav, out: out}
+}
+
+// Run runs the subcommand, plain logs by default.
+func (h *Headless) Run(ctx context.Context) error {
+	switch h.cfg.Command {
+	case config.CommandLs:
+		return h.Ls()
+	case config.CommandGrep:
+		return h.Grep(ctx)
+	default:
+		return h.Logs(ctx)
+	}
+}
+
+func (h *Headless) Ls() error {
+	targets := h.targets()
+
+	if h.cfg.JSON {
+		type item struct {
+			ID    string `json:"id"`
+			Name  string `json:"name"`
+			Image string `json:"image,omitempty"`
+		}
+
+		list := make([]item, 0, len(targets))
+		for _, t := range targets {
+			list = append(list, item{ID: t.ID, Name: strings.TrimPrefix(t.Name, "/"), Image: t.Image})
+		}
+
+		enc := json.NewEncoder(h.out)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(list)
+	}
+
+	tw := tabwriter.NewWriter(h.out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tID\tIMAGE")
+
+	for _, t := range targets {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", strings.TrimPrefix(t.Name, "/"), source.ShortID(t.ID), t.Image)
+	}
+
+	return tw.Flush()
+}
+
+// Logs streams the targets; without follow the lines of several
+// targets are merged by their timestamps.
+func (h *Headless) Logs(ctx context.Context) error {
+	opts, err := h.options()
+	if err != nil {
+		return err
+	}
+
+	targets := h.targets()
+	if len(targets) == 0 {
+		return ErrNoTargets
+	}
+
+	prefix := h.prefixer(targets)
+
+	if opts.Follow || len(targets) == 1 {
+		return h.each(ctx, targets, opts, func(t source.Target) io.Writer {
+			return &lineWriter{fn: func(line string) {
+				h.writeLine(prefix(t) + line)
+			}}
+		})
+	}
+
+	outputs := make([]*bytes.Buffer, len(targets))
+	byTarget := make(map[string]*bytes.Buffer, len(targets))
+
+	for i, t := range targets {
+		outputs[i] = &bytes.Buffer{}
+		byTarget[t.ID] = outputs[i]
+	}
+
+	err = h.each(ctx, targets, opts, func(t source.Target) io.Writer {
+		return byTarget[t.ID]
+	})
+
+	for _, e := range merge(targets, outputs) {
+		h.writeLine(prefix(e.target) + e.line)
+	}
+
+	return err
+}
+
+// Grep prints the lines of the targets the searcher matches.
+func (h *Headless) Grep(ctx context.Context) error {
+	if h.cfg.Pattern == "" {
+		return errors.New("grep needs a pattern")
+	}
+
+	opts, err := h.options()
+	if err != nil {
+		return err
+	}
+
+	targets := h.targets()
+	if len(targets) == 0 {
+		return ErrNoTargets
+	}
+
+	searcher := oviewer.NewWordSearcher(h.cfg.Pattern, h.cfg.CaseSensitive, h.cfg.Regexp)
+	prefix := h.prefixer(targets)
+
+	write := func(t source.Target) io.Writer {
+		return &lineWriter{fn: func(line string) {
+			if searcher.Match(line) {
+				h.writeLine(prefix(t) + line)
+			}
+		}}
+	}
+
+	if opts.Follow {
+		return h.each(ctx, targets, opts, write)
+	}
+
+	for _, t := range targets {
+		w := write(t)
+		if err := h.nav.OpenTarget(ctx, t, w, opts); err != nil {
+			return err
+		}
+
+		w.(*lineWriter).flush()
+	}
+
+	return nil
+}
+
+func (h *Headless) each(ctx context.Context, targets []source.Target, opts source.Options,
+	writer func(t source.Target) io.Writer,
+) error {
+	var (
+		wg    sync.WaitGroup
+		errMu sync.Mutex
+		first error
+	)
+
+	for _, t := range targets {
+		t, w := t, writer(t)
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			err := h.nav.OpenTarget(ctx, t, w, opts)
+			if lw, ok := w.(*lineWriter); ok {
+				lw.flush()
+			}
+
+			if err != nil {
+				errMu.Lock()
+				if first == nil {
+					first = errors.Wrap(err, t.Name)
+				}
+				errMu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return first
+}
+
+func (h *Headless) options() (source.Options, error) {
+	opts := source.Options{Tail: h.cfg.Tail, Follow: h.cfg.Follow}
+
+	// Grep searches the whole logs unless a tail is given.
+	if h.cfg.Command == config.CommandGrep && !h.cfg.TailSet {
+		opts.Tail = 0
+	}
+
+	var err error
+
+	if opts.Since, err = ParseTime(h.cfg.Since, time.Now()); err != nil {
+		return opts, errors.Wrap(err, "invalid since")
+	}
+
+	if opts.Until, err = ParseTime(h.cfg.Until, time.Now()); err != nil {
+		return opts, errors.Wrap(err, "invalid until")
+	}
+
+	return opts, nil
+}
+
+// targets returns the targets selected by the arguments.
+func (h *Headless) targets() []source.Target {
+	all := h.nav.Targets()
+	if len(h.cfg.Containers) == 0 {
+		return all
+	}
+
+	var targets []source.Target
+
+	for _, t := range all {
+		for _, c := range h.cfg.Containers {
+			if strings.Contains(t.Name, c) || strings.HasPrefix(t.ID, c) {
+				targets = append(targets, t)
+
+				break
+			}
+		}
+	}
+
+	return targets
+}
+
+func (h *Headless) prefixer(targets []source.Target) func(t source.Target) string {
+	if h.cfg.NoPrefix || len(targets) < 2 {
+		return func(source.Target) string { return "" }
+	}
+
+	width := 0
+	for _, t := range targets {
+		if n := len(strings.TrimPrefix(t.Name, "/")); n > width {
+			width = n
+		}
+	}
+
+	return func(t source.Target) string {
+		return fmt.Sprintf("%-*s | ", width, strings.TrimPrefix(t.Name, "/"))
+	}
+}
+
+func (h *Headless) writeLine(line string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, _ = io.WriteString(h.out, line+"\n")
+}
+
+// lineWriter calls fn for every complete line written.
+type lineWriter struct {
+	fn  func(line string)
+	buf []byte
+	mu  sync.Mutex
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		w.fn(strings.TrimSuffix(string(w.buf[:i]), "\r"))
+		w.buf = w.buf[i+1:]
+	}
+
+	return len(p), nil
+}
+
+func (w *lineWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.fn(string(w.buf))
+		w.buf = nil
+	}
+}
+
+type entry struct {
+	target source.Target
+	time   time.Time
+	line   string
+}
+
+// merge orders the lines of all targets by their leading timestamps.
+// Lines without a timestamp keep the time of the preceding line.
+func merge(targets []source.Target, outputs []*bytes.Buffer) []entry {
+	var entries []entry
+
+	for i, out := range outputs {
+		var last time.Time
+
+		scanner := bufio.NewScanner(out)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+		for scanner.Scan() {
+			line := scanner.Text()
+			if t, ok := timestamp.Line(line); ok {
+				last = t
+			}
+
+			entries = append(entries, entry{target: targets[i], time: last, line: line})
+		}
+	}
+
+	sortEntries(entries)
+
+	return entries
+}
@@ -0,0 +1,68 @@
+package headless
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/dimcz/viewer/pkg/source"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"", time.Time{}},
+		{"15m", now.Add(-15 * time.Minute)},
+		{"-1h", now.Add(-time.Hour)},
+		{"2022-06-01T10:00:00Z", time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTime(tt.in, now)
+		if err != nil {
+			t.Fatalf("ParseTime(%q) error = %v", tt.in, err)
+		}
+
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := ParseTime("yesterday", now); err == nil {
+		t.Error("ParseTime(yesterday) expected an error")
+	}
+}
+
+func Test_merge(t *testing.T) {
+	targets := []source.Target{{ID: "a", Name: "a"}, {ID: "b", Name: "b"}}
+	outputs := []*bytes.Buffer{
+		bytes.NewBufferString("2022-06-01T10:00:01Z first\n  continued\n2022-06-01T10:00:03Z third\n"),
+		bytes.NewBufferString("2022-06-01T10:00:02Z second\n"),
+	}
+
+	var got []string
+	for _, e := range merge(targets, outputs) {
+		got = append(got, e.target.Name+" "+e.line)
+	}
+
+	want := []string{
+		"a 2022-06-01T10:00:01Z first",
+		"a   continued",
+		"b 2022-06-01T10:00:02Z second",
+		"a 2022-06-01T10:00:03Z third",
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("merge() = %q, want %q", got, want)
+	}
+
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("merge()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package headless
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// ParseTime parses an RFC3339 time or a duration before now (e.g. 15m).
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(strings.TrimPrefix(s, "-")); err == nil {
+		return now.Add(-d), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+
+	return time.ParseInLocation("2006-01-02T15:04:05", s, time.Local)
+}
+
+// sortEntries keeps the order of lines with equal times,
+// so a target's lines never change places.
+func sortEntries(entries []entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].time.Before(entries[j].time)
+	})
+}
@@ -0,0 +1,142 @@
+package viewer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dimcz/viewer/pkg/docker"
+	"github.com/dimcz/viewer/pkg/oviewer"
+	"github.com/dimcz/viewer/pkg/source"
+)
+
+const (
+	panelDelimiter = "|"
+	topInterval    = 2
+)
+
+var changeKinds = []string{"C", "A", "D"}
+
+func (v *Viewer) topPanel() {
+	dock := v.docker()
+	if dock == nil {
+		v.ov.SetMessage("docker top is not available")
+
+		return
+	}
+
+	// The panel is regenerated on reloads, it keeps showing the target
+	// it was opened on after switching to another one.
+	t, caption := v.nav.Current(), v.nav.Name()
+
+	go func() {
+		doc, err := v.newPanel("top", caption, func(w io.Writer) error {
+			return v.writeTop(w, dock, t)
+		})
+		if err != nil {
+			v.log.Error("failed to create top panel: ", err)
+
+			return
+		}
+
+		doc.WatchMode = true
+		doc.WatchInterval = topInterval
+
+		v.ov.DisplayPanel(doc)
+	}()
+}
+
+func (v *Viewer) diffPanel() {
+	dock := v.docker()
+	if dock == nil {
+		v.ov.SetMessage("docker diff is not available")
+
+		return
+	}
+
+	t, caption := v.nav.Current(), v.nav.Name()
+
+	go func() {
+		doc, err := v.newPanel("diff", caption, func(w io.Writer) error {
+			return v.writeDiff(w, dock, t)
+		})
+		if err != nil {
+			v.log.Error("failed to create diff panel: ", err)
+
+			return
+		}
+
+		v.ov.DisplayPanel(doc)
+	}()
+}
+
+// newPanel generates the first contents of a panel. It calls docker,
+// so it runs outside the event loop; reloads run in the background too.
+func (v *Viewer) newPanel(name, caption string, generate func(w io.Writer) error) (*oviewer.Document, error) {
+	doc, err := oviewer.GenerateDocument(generate)
+	if err != nil {
+		return nil, err
+	}
+
+	doc.Caption = fmt.Sprintf("[%s] %s", name, caption)
+	doc.Header = 1
+	doc.ColumnMode = true
+	doc.ColumnDelimiter = panelDelimiter
+	doc.SetLog(v.log.Debug)
+
+	return doc, nil
+}
+
+func (v *Viewer) writeTop(w io.Writer, dock *docker.Docker, t source.Target) error {
+	top, err := dock.Top(v.ctx, t)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(top.Processes)+1)
+	rows = append(rows, top.Titles)
+	rows = append(rows, top.Processes...)
+
+	return writeTable(w, rows)
+}
+
+func (v *Viewer) writeDiff(w io.Writer, dock *docker.Docker, t source.Target) error {
+	changes, err := dock.Diff(v.ctx, t)
+	if err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(changes)+1)
+	rows = append(rows, []string{"KIND", "PATH"})
+
+	for _, c := range changes {
+		kind := "?"
+		if int(c.Kind) < len(changeKinds) {
+			kind = changeKinds[c.Kind]
+		}
+
+		rows = append(rows, []string{kind, c.Path})
+	}
+
+	return writeTable(w, rows)
+}
+
+// docker returns the docker source of the current target, if any.
+func (v *Viewer) docker() *docker.Docker {
+	dock, _ := v.nav.Source().(*docker.Docker)
+
+	return dock
+}
+
+func writeTable(w io.Writer, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', tabwriter.Debug)
+
+	for _, row := range rows {
+		if _, err := fmt.Fprintln(tw, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,57 @@
+package viewer
+
+import (
+	"time"
+
+	"github.com/dimcz/viewer/pkg/replay"
+)
+
+const (
+	seekStep    = 10 * time.Second
+	speedFactor = 2
+)
+
+func (v *Viewer) replayPause() {
+	v.withReplay(func(r *replay.Replay) {
+		r.TogglePause()
+	})
+}
+
+func (v *Viewer) replayForward() {
+	v.withReplay(func(r *replay.Replay) {
+		r.Seek(seekStep)
+	})
+}
+
+func (v *Viewer) replayBackward() {
+	v.withReplay(func(r *replay.Replay) {
+		// The lines after the new position are already in the document.
+		if r.Seek(-seekStep) {
+			v.reload()
+		}
+	})
+}
+
+func (v *Viewer) replayFaster() {
+	v.withReplay(func(r *replay.Replay) {
+		r.SetSpeed(speedFactor)
+	})
+}
+
+func (v *Viewer) replaySlower() {
+	v.withReplay(func(r *replay.Replay) {
+		r.SetSpeed(1.0 / speedFactor)
+	})
+}
+
+func (v *Viewer) withReplay(fn func(r *replay.Replay)) {
+	r, ok := v.nav.Source().(*replay.Replay)
+	if !ok {
+		v.ov.SetMessage("replay is not active")
+
+		return
+	}
+
+	fn(r)
+	v.ov.SetMessage(r.Status())
+}
@@ -0,0 +1,247 @@
+package viewer
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/dimcz/viewer/pkg/oviewer"
+	"github.com/dimcz/viewer/pkg/snapshot"
+	"github.com/dimcz/viewer/pkg/source"
+	"github.com/pkg/errors"
+)
+
+const snapshotLayout = "20060102-150405"
+
+type session struct {
+	target   source.Target
+	cache    string
+	marks    []int
+	anchors  []uint64
+	mode     string
+	manual   string
+	search   string
+	view     *viewState
+	attached time.Time
+	detached time.Time
+}
+
+func (v *Viewer) attach() {
+	t := v.nav.Current()
+
+	s, ok := v.sessions[t.ID]
+	if !ok {
+		s = &session{target: t}
+		s.marks, _ = v.nav.Marks()
+		s.mode = v.cfg.ModeFor(t)
+
+		if st, ok := v.state.Targets[stateKey(t)]; ok {
+			s.restoreState(st)
+		}
+
+		v.sessions[t.ID] = s
+	}
+
+	if s.cache != "" && s.cache != v.cache.Name() {
+		v.log.LogOnErr(os.Remove(s.cache))
+	}
+
+	s.cache = v.cache.Name()
+	s.attached = time.Now()
+	s.detached = time.Time{}
+}
+
+func (v *Viewer) detach() {
+	s := v.current()
+	if s == nil {
+		return
+	}
+
+	s.detached = time.Now()
+
+	if v.ov != nil {
+		doc := v.ov.DocList[v.ov.CurrentDoc]
+		s.marks = doc.Marks()
+		s.anchors = doc.MarkAnchors()
+		s.search = v.ov.LastSearch()
+
+		s.view = newViewState(doc.General())
+
+		// A mode chosen by hand replaces the one of the rules
+		// for the rest of the session.
+		if mode := doc.ViewMode(); mode != "" {
+			s.manual = mode
+		}
+	}
+}
+
+// applyView sets the mode of the current session on the document shown
+// next, then restores its column and wrap settings and last search.
+// The search history is shared by all targets.
+func (v *Viewer) applyView(doc *oviewer.Document) {
+	s := v.current()
+	if s == nil {
+		return
+	}
+
+	v.ov.SetLastSearch(s.search)
+
+	if mode := s.viewMode(); mode != "" {
+		v.ov.SetViewMode(mode)
+	}
+
+	if s.view == nil {
+		return
+	}
+
+	view := *s.view
+
+	// Called after the mode event, so the settings apply over the mode.
+	v.ov.Call(func() {
+		doc.SetGeneral(view.apply(doc.General()))
+		v.ov.ViewSync()
+	})
+}
+
+// viewMode returns the mode chosen by hand, or else the one of the rules.
+func (s *session) viewMode() string {
+	if s.manual != "" {
+		return s.manual
+	}
+
+	return s.mode
+}
+
+func (s *session) restoreState(st *targetState) {
+	s.anchors = st.Marks
+	s.search = st.Search
+	s.view = st.View
+}
+
+func (v *Viewer) current() *session {
+	return v.sessions[v.nav.Current().ID]
+}
+
+func (v *Viewer) removeSessions() {
+	for _, s := range v.sessions {
+		if err := os.Remove(s.cache); err != nil && !os.IsNotExist(err) {
+			v.log.Error(err)
+		}
+	}
+}
+
+// export is a target of an exported session.
+type export struct {
+	target snapshot.Target
+	source source.Target
+	cache  string
+}
+
+// exportSession writes the snapshot in the background, as it reads
+// the logs and asks the sources, and reports the result on the status line.
+func (v *Viewer) exportSession() {
+	if v.exporting {
+		v.ov.SetMessage("Export in progress")
+
+		return
+	}
+
+	fileName := fmt.Sprintf("dview-%s.tar.zst", time.Now().Format(snapshotLayout))
+	exports := v.exports()
+
+	v.exporting = true
+	v.ov.SetMessage("Exporting session to " + fileName)
+
+	go func() {
+		err := v.writeSnapshot(fileName, exports)
+
+		v.ov.Call(func() {
+			v.exporting = false
+
+			if err != nil {
+				v.log.Error("failed to export session: ", err)
+				v.ov.SetMessage(fmt.Sprintf("Export failed: %s", err))
+
+				return
+			}
+
+			v.ov.SetMessage("Exported session to " + fileName)
+		})
+	}()
+}
+
+// exports returns the targets of the session. It runs in the event loop,
+// which owns the sessions and the marks.
+func (v *Viewer) exports() []export {
+	if s := v.current(); s != nil {
+		s.marks = v.ov.DocList[v.ov.CurrentDoc].Marks()
+	}
+
+	sessions := v.sortedSessions()
+	exports := make([]export, 0, len(sessions))
+
+	for _, s := range sessions {
+		exports = append(exports, export{
+			target: snapshot.Target{
+				Name:     s.target.Name,
+				ID:       s.target.ID,
+				Image:    s.target.Image,
+				Labels:   s.target.Labels,
+				Attached: s.attached,
+				Detached: s.detached,
+				Marks:    s.marks,
+				Log:      snapshot.LogName(s.target.ID),
+			},
+			source: s.target,
+			cache:  s.cache,
+		})
+	}
+
+	return exports
+}
+
+func (v *Viewer) writeSnapshot(fileName string, exports []export) error {
+	w, err := snapshot.Create(fileName)
+	if err != nil {
+		return err
+	}
+
+	manifest := snapshot.Manifest{Created: time.Now()}
+
+	for _, e := range exports {
+		t := e.target
+
+		if err := w.AddFile(t.Log, e.cache); err != nil {
+			w.Abort()
+
+			return errors.Wrap(err, "failed to add log")
+		}
+
+		if raw, err := v.nav.Inspect(v.ctx, e.source); err == nil {
+			t.Inspect = snapshot.InspectName(t.ID)
+			if err := w.AddBytes(t.Inspect, raw); err != nil {
+				w.Abort()
+
+				return errors.Wrap(err, "failed to add inspect")
+			}
+		}
+
+		manifest.Targets = append(manifest.Targets, t)
+	}
+
+	return w.Close(manifest)
+}
+
+func (v *Viewer) sortedSessions() []*session {
+	list := make([]*session, 0, len(v.sessions))
+	for _, s := range v.sessions {
+		list = append(list, s)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].attached.Before(list[j].attached)
+	})
+
+	return list
+}
@@ -0,0 +1,215 @@
+package viewer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dimcz/viewer/internal/config"
+	"github.com/dimcz/viewer/pkg/oviewer"
+	"github.com/dimcz/viewer/pkg/source"
+)
+
+const (
+	stateFileName   = "state.json"
+	historyFileName = "history.json"
+
+	composeProject = "com.docker.compose.project"
+	composeService = "com.docker.compose.service"
+)
+
+// targetState is what is kept of a target between runs.
+// Marks are the anchors of the marked lines, which find the lines again
+// when the logs are read from another line. Search is the last search;
+// the search history is in the history file.
+type targetState struct {
+	Marks  []uint64   `json:"mark_anchors,omitempty"`
+	Search string     `json:"search,omitempty"`
+	View   *viewState `json:"view,omitempty"`
+}
+
+// viewState is the column and wrap settings of a target. They are
+// applied over the settings of the config and the mode of the target.
+type viewState struct {
+	ColumnMode      bool   `json:"column_mode"`
+	ColumnDelimiter string `json:"column_delimiter,omitempty"`
+	WrapMode        bool   `json:"wrap_mode"`
+}
+
+type state struct {
+	Last    string                  `json:"last,omitempty"`
+	Targets map[string]*targetState `json:"targets,omitempty"`
+}
+
+func newViewState(g oviewer.General) *viewState {
+	return &viewState{
+		ColumnMode:      g.ColumnMode,
+		ColumnDelimiter: g.ColumnDelimiter,
+		WrapMode:        g.WrapMode,
+	}
+}
+
+// apply sets the saved settings over g.
+func (vs viewState) apply(g oviewer.General) oviewer.General {
+	g.ColumnMode = vs.ColumnMode
+	g.WrapMode = vs.WrapMode
+
+	if vs.ColumnDelimiter != "" {
+		g.ColumnDelimiter = vs.ColumnDelimiter
+	}
+
+	return g
+}
+
+// StateFile returns the state file in $XDG_STATE_HOME/dview,
+// ~/.local/state/dview by default.
+func StateFile() string {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+
+		dir = filepath.Join(home, ".local", "state")
+	}
+
+	return filepath.Join(dir, config.PluginName, stateFileName)
+}
+
+// HistoryFile returns the file of the input histories next to the state file.
+func HistoryFile() string {
+	fileName := StateFile()
+	if fileName == "" {
+		return ""
+	}
+
+	return filepath.Join(filepath.Dir(fileName), historyFileName)
+}
+
+// stateKey keys the state by the compose service,
+// so it survives containers being recreated.
+func stateKey(t source.Target) string {
+	if service, ok := t.Labels[composeService]; ok {
+		return t.Labels[composeProject] + "/" + service
+	}
+
+	return strings.TrimPrefix(t.Name, "/")
+}
+
+func loadState(fileName string) (*state, error) {
+	st := &state{Targets: make(map[string]*targetState)}
+
+	data, err := os.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return st, nil
+	}
+
+	if err != nil {
+		return st, err
+	}
+
+	if err := json.Unmarshal(data, st); err != nil {
+		return st, err
+	}
+
+	if st.Targets == nil {
+		st.Targets = make(map[string]*targetState)
+	}
+
+	return st, nil
+}
+
+// save merges the targets into the state file, so the state
+// of targets not shown in this run is kept.
+func (st *state) save(fileName string) error {
+	saved, err := loadState(fileName)
+	if err != nil {
+		saved = &state{Targets: make(map[string]*targetState)}
+	}
+
+	for key, t := range st.Targets {
+		saved.Targets[key] = t
+	}
+
+	if st.Last != "" {
+		saved.Last = st.Last
+	}
+
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fileName), 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(fileName), stateFileName+".*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	return os.Rename(tmp.Name(), fileName)
+}
+
+// restoreLast makes the target viewed last current.
+func (v *Viewer) restoreLast() {
+	if v.state.Last == "" {
+		return
+	}
+
+	for _, t := range v.nav.Targets() {
+		if stateKey(t) == v.state.Last {
+			v.nav.Select(t.ID)
+
+			return
+		}
+	}
+}
+
+// saveState writes the state of the targets shown in this run.
+func (v *Viewer) saveState() {
+	if v.ov == nil {
+		return
+	}
+
+	v.detach()
+
+	st := &state{Targets: make(map[string]*targetState)}
+
+	if t := v.nav.Current(); t.ID != "" {
+		st.Last = stateKey(t)
+	}
+
+	for id, s := range v.sessions {
+		if id == "" {
+			continue
+		}
+
+		st.Targets[stateKey(s.target)] = &targetState{
+			Marks:  s.anchors,
+			Search: s.search,
+			View:   s.view,
+		}
+	}
+
+	if err := st.save(StateFile()); err != nil {
+		v.log.Error("failed to save state: ", err)
+	}
+}
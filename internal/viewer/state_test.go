@@ -0,0 +1,113 @@
+package viewer
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dimcz/viewer/pkg/oviewer"
+	"github.com/dimcz/viewer/pkg/source"
+)
+
+func Test_stateKey(t *testing.T) {
+	tests := []struct {
+		target source.Target
+		want   string
+	}{
+		{source.Target{Name: "/web-1"}, "web-1"},
+		{source.Target{Name: "/shop_web_1", Labels: map[string]string{
+			composeProject: "shop",
+			composeService: "web",
+		}}, "shop/web"},
+	}
+
+	for _, tt := range tests {
+		if got := stateKey(tt.target); got != tt.want {
+			t.Errorf("stateKey(%s) = %q, want %q", tt.target.Name, got, tt.want)
+		}
+	}
+}
+
+func Test_state_save(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "dview", stateFileName)
+
+	first := &state{Last: "db", Targets: map[string]*targetState{
+		"db":  {Marks: []uint64{1, 5}},
+		"web": {Search: "error"},
+	}}
+	if err := first.save(fileName); err != nil {
+		t.Fatal(err)
+	}
+
+	second := &state{Last: "web", Targets: map[string]*targetState{
+		"web": {Search: "timeout", View: &viewState{ColumnMode: true}},
+	}}
+	if err := second.save(fileName); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := loadState(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &state{Last: "web", Targets: map[string]*targetState{
+		"db":  {Marks: []uint64{1, 5}},
+		"web": {Search: "timeout", View: &viewState{ColumnMode: true}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loadState() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_viewState_apply(t *testing.T) {
+	mode := oviewer.General{
+		ColumnDelimiter: ",",
+		WrapMode:        true,
+		MinLevel:        oviewer.LevelWarn,
+		Filters:         []oviewer.FilterRule{{Pattern: "health", Exclude: true}},
+	}
+
+	got := viewState{ColumnMode: true, WrapMode: false}.apply(mode)
+
+	want := mode
+	want.ColumnMode = true
+	want.WrapMode = false
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("apply() = %+v, want %+v", got, want)
+	}
+
+	got = viewState{ColumnMode: true, ColumnDelimiter: "|"}.apply(mode)
+	if got.ColumnDelimiter != "|" {
+		t.Errorf("apply() delimiter = %q, want %q", got.ColumnDelimiter, "|")
+	}
+}
+
+func Test_session_restoreState(t *testing.T) {
+	st := &targetState{Marks: []uint64{3}, Search: "error"}
+
+	s := &session{mode: "json"}
+	s.restoreState(st)
+
+	if !reflect.DeepEqual(s.anchors, st.Marks) || s.marks != nil {
+		t.Errorf("anchors = %v, marks = %v, want %v, nil", s.anchors, s.marks, st.Marks)
+	}
+
+	// The mode of the rules is kept.
+	if s.viewMode() != "json" || s.search != "error" {
+		t.Errorf("viewMode() = %q, search = %q", s.viewMode(), s.search)
+	}
+}
+
+func Test_session_viewMode(t *testing.T) {
+	s := &session{mode: "nginx"}
+	if got := s.viewMode(); got != "nginx" {
+		t.Errorf("viewMode() = %q, want the rule mode", got)
+	}
+
+	s.manual = "plain"
+	if got := s.viewMode(); got != "plain" {
+		t.Errorf("viewMode() = %q, want the mode chosen by hand", got)
+	}
+}
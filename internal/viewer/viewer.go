@@ -2,15 +2,17 @@ package viewer
 
 import (
 	"context"
+	"io"
 	"io/ioutil"
 	"os"
 	"runtime"
 
 	"code.cloudfoundry.org/bytefmt"
 	"github.com/dimcz/viewer/internal/config"
-	"github.com/dimcz/viewer/pkg/docker"
 	"github.com/dimcz/viewer/pkg/logger"
 	"github.com/dimcz/viewer/pkg/oviewer"
+	"github.com/dimcz/viewer/pkg/recorder"
+	"github.com/dimcz/viewer/pkg/source"
 	"github.com/pkg/errors"
 )
 
@@ -20,34 +22,71 @@ type Viewer struct {
 	ctx    context.Context
 	cancel func()
 
-	dock  *docker.Docker
+	nav   *source.Navigator
 	cache *os.File
+	rec   *recorder.Recorder
+
+	sessions  map[string]*session
+	state     *state
+	exporting bool
 
 	ov *oviewer.Root
 }
 
-func Init(log *logger.Logger, cfg *config.Config, dock *docker.Docker) (*Viewer, error) {
-	ctx, cancel := context.WithCancel(context.Background())
+func Init(log *logger.Logger, cfg *config.Config, nav *source.Navigator) (*Viewer, error) {
+	v := &Viewer{
+		log:      log,
+		cfg:      cfg,
+		nav:      nav,
+		sessions: make(map[string]*session),
+	}
+
+	if cfg.Record != "" {
+		rec, err := recorder.New(log, cfg.Record, cfg.RecordSize, cfg.RecordInterval, cfg.RecordCompress)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create recorder")
+		}
+
+		v.rec = rec
+	}
+
+	v.state = &state{Targets: make(map[string]*targetState)}
+
+	// A snapshot has its own marks and is not saved over the live state.
+	if !cfg.Fresh && cfg.Open == "" {
+		st, err := loadState(StateFile())
+		if err != nil {
+			log.Error("failed to load state: ", err)
+		}
 
-	return &Viewer{
-		log:    log,
-		cfg:    cfg,
-		ctx:    ctx,
-		cancel: cancel,
-		dock:   dock,
-	}, nil
+		v.state = st
+	}
+
+	v.ctx, v.cancel = context.WithCancel(context.Background())
+
+	return v, nil
 }
 
 func (v *Viewer) Shutdown() {
+	if v.cfg.Open == "" {
+		v.saveState()
+	}
+
 	v.ov.Close()
 	v.cancel()
 
-	if err := os.Remove(v.cache.Name()); err != nil {
-		v.log.Error(err)
+	if v.rec != nil {
+		v.rec.Close()
 	}
+
+	v.removeSessions()
 }
 
 func (v *Viewer) Start() error {
+	v.restoreLast()
+
+	oviewer.HistoryFile = HistoryFile()
+
 	doc, err := v.newDocument()
 	if err != nil {
 		return errors.Wrap(err, "failed to create document")
@@ -58,38 +97,56 @@ func (v *Viewer) Start() error {
 		return errors.Wrap(err, "failed to create oviewer")
 	}
 
+	v.nav.Watch(v.ctx, func() {
+		v.ov.Call(v.reload)
+	})
+
+	v.ov.SetConfig(v.cfg.Viewer)
 	v.ov.SetLog(v.log.Debug)
-	v.ov.General.FollowMode = true
-	v.ov.General.WrapMode = true
+	v.applyView(doc)
 
-	if err := v.ov.SetKeyHandler("prevContainer", []string{"left"}, v.PrevContainer); err != nil {
-		return errors.Wrap(err, "failed to bind left key")
+	if err := v.bindKeys(); err != nil {
+		return err
 	}
 
-	if err := v.ov.SetKeyHandler("nextContainer", []string{"right"}, v.NextContainer); err != nil {
-		return errors.Wrap(err, "failed to bind right key")
+	if err := v.ov.Run(); err != nil {
+		return errors.Wrap(err, "failed to run oviewer")
 	}
 
-	if err := v.ov.SetKeyHandler("systemReport", []string{"s"}, v.systemReport); err != nil {
-		return errors.Wrap(err, "failed to bind s key")
-	}
+	return nil
+}
 
-	if err := v.ov.SetKeyHandler("allLogs", []string{"ctrl+y"}, v.retrieveAllLogs); err != nil {
-		return errors.Wrap(err, "failed to bind s key")
+// bindKeys binds the dview actions to the configured keys.
+func (v *Viewer) bindKeys() error {
+	handlers := map[string]func(){
+		"prevContainer":  v.PrevContainer,
+		"nextContainer":  v.NextContainer,
+		"systemReport":   v.systemReport,
+		"allLogs":        v.retrieveAllLogs,
+		"topPanel":       v.topPanel,
+		"diffPanel":      v.diffPanel,
+		"exportSession":  v.exportSession,
+		"replayPause":    v.replayPause,
+		"replayForward":  v.replayForward,
+		"replayBackward": v.replayBackward,
+		"replayFaster":   v.replayFaster,
+		"replaySlower":   v.replaySlower,
 	}
 
-	if err := v.ov.Run(); err != nil {
-		return errors.Wrap(err, "failed to run oviewer")
+	for name, handler := range handlers {
+		if err := v.ov.SetKeyHandler(name, v.cfg.Keybind[name], handler); err != nil {
+			return errors.Wrapf(err, "failed to bind %s", name)
+		}
 	}
 
 	return nil
 }
 
 func (v *Viewer) Stop() {
-	v.dock.Stop()
+	v.nav.Stop()
+	v.detach()
 
 	v.log.LogOnErr(v.cache.Close())
-	v.log.LogOnErr(os.Remove(v.cache.Name()))
 }
 
 func (v *Viewer) NewDocument() error {
@@ -100,14 +157,25 @@ func (v *Viewer) NewDocument() error {
 	}
 
 	v.ov.ReplaceDocument(doc)
+	v.applyView(doc)
 
 	return nil
 }
 
+// reload shows the current target again, e.g. the first one
+// received by a listener.
+func (v *Viewer) reload() {
+	v.Stop()
+
+	if err := v.NewDocument(); err != nil {
+		v.log.Fatal(err)
+	}
+}
+
 func (v *Viewer) PrevContainer() {
 	v.Stop()
 
-	v.dock.SetPrevContainer()
+	v.nav.SetPrev()
 
 	if err := v.NewDocument(); err != nil {
 		v.log.Fatal(err)
@@ -117,7 +185,7 @@ func (v *Viewer) PrevContainer() {
 func (v *Viewer) NextContainer() {
 	v.Stop()
 
-	v.dock.SetNextContainer()
+	v.nav.SetNext()
 
 	if err := v.NewDocument(); err != nil {
 		v.log.Fatal(err)
@@ -125,6 +193,10 @@ func (v *Viewer) NextContainer() {
 }
 
 func (v *Viewer) newDocument() (*oviewer.Document, error) {
+	return v.loadDocument(v.cfg.Tail)
+}
+
+func (v *Viewer) loadDocument(tail int) (*oviewer.Document, error) {
 	var err error
 
 	v.cache, err = ioutil.TempFile(os.TempDir(), "dlog_")
@@ -132,19 +204,50 @@ func (v *Viewer) newDocument() (*oviewer.Document, error) {
 		return nil, errors.Wrap(err, "failed to create temp file")
 	}
 
-	v.dock.Load(v.ctx, v.cache, v.cfg.Tail)
+	v.nav.Load(v.ctx, v.output(), tail)
+	v.attach()
 
 	doc, err := oviewer.OpenDocument(v.cache.Name())
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to open document")
 	}
 
-	doc.Caption = v.dock.Name()
+	doc.Caption = v.nav.Name()
+	doc.Metadata = v.nav.Metadata()
 	doc.SetLog(v.log.Debug)
 
+	// The logs of a snapshot do not change between attaches, so its marks
+	// stay valid. Other logs start at another line on every attach, so
+	// their marks are found again by the anchors of the marked lines.
+	if s := v.current(); s != nil {
+		if _, ok := v.nav.Marks(); ok {
+			doc.SetMarks(s.marks)
+		} else if len(s.anchors) > 0 {
+			doc.SetMarkAnchors(s.anchors)
+		}
+	}
+
 	return doc, nil
 }
 
+// output returns the writer the current target is loaded into:
+// the cache, and the record file when recording.
+func (v *Viewer) output() io.Writer {
+	t := v.nav.Current()
+	if v.rec == nil || t.ID == "" {
+		return v.cache
+	}
+
+	w, err := v.rec.Attach(t.Name)
+	if err != nil {
+		v.log.Error("failed to record: ", err)
+
+		return v.cache
+	}
+
+	return io.MultiWriter(v.cache, w)
+}
+
 func (v *Viewer) systemReport() {
 	var mem runtime.MemStats
 
@@ -162,22 +265,11 @@ func (v *Viewer) systemReport() {
 func (v *Viewer) retrieveAllLogs() {
 	v.Stop()
 
-	var err error
-
-	v.cache, err = ioutil.TempFile(os.TempDir(), "dlog_")
+	doc, err := v.loadDocument(0)
 	if err != nil {
 		v.log.Fatal(err)
 	}
 
-	v.dock.Load(v.ctx, v.cache, 0)
-
-	doc, err := oviewer.OpenDocument(v.cache.Name())
-	if err != nil {
-		v.log.Fatal(err)
-	}
-
-	doc.Caption = v.dock.Name()
-	doc.SetLog(v.log.Debug)
-
 	v.ov.ReplaceDocument(doc)
+	v.applyView(doc)
 }
@@ -0,0 +1,147 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const defaultContext = "default"
+
+var (
+	ErrNoContext = errors.New("docker context not found")
+	// ErrSSHHost is returned for ssh:// hosts, which the docker client
+	// cannot dial without the ssh helper of the docker CLI.
+	ErrSSHHost = errors.New("ssh docker hosts and contexts are not supported")
+)
+
+// Endpoint is the daemon of a docker context.
+type Endpoint struct {
+	Host string
+
+	// TLS files of the context, empty when it has none.
+	CACert string
+	Cert   string
+	Key    string
+}
+
+type contextMeta struct {
+	Name      string `json:"Name"`
+	Endpoints struct {
+		Docker struct {
+			Host string `json:"Host"`
+		} `json:"docker"`
+	} `json:"Endpoints"`
+}
+
+// ConfigDir returns the docker CLI configuration directory.
+func ConfigDir(dir string) string {
+	if dir != "" {
+		return dir
+	}
+
+	if env := os.Getenv("DOCKER_CONFIG"); env != "" {
+		return env
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".docker")
+}
+
+// ResolveEndpoint picks the daemon the way the docker CLI does: the host,
+// the named context, DOCKER_HOST, DOCKER_CONTEXT, then the current context
+// of config.json. A nil endpoint means the defaults of the environment.
+// Daemons reached over ssh are rejected with ErrSSHHost.
+func ResolveEndpoint(configDir, contextName, host string) (*Endpoint, error) {
+	ep, err := resolveEndpoint(configDir, contextName, host)
+	if err != nil {
+		return nil, err
+	}
+
+	host = os.Getenv("DOCKER_HOST")
+	if ep != nil {
+		host = ep.Host
+	}
+
+	if strings.HasPrefix(host, "ssh://") {
+		return nil, errors.Wrap(ErrSSHHost, host)
+	}
+
+	return ep, nil
+}
+
+func resolveEndpoint(configDir, contextName, host string) (*Endpoint, error) {
+	if host != "" {
+		return &Endpoint{Host: host}, nil
+	}
+
+	if contextName == "" {
+		if os.Getenv("DOCKER_HOST") != "" {
+			return nil, nil
+		}
+
+		contextName = os.Getenv("DOCKER_CONTEXT")
+	}
+
+	if contextName == "" {
+		contextName = currentContext(configDir)
+	}
+
+	if contextName == "" || contextName == defaultContext {
+		return nil, nil
+	}
+
+	return loadContext(configDir, contextName)
+}
+
+func currentContext(configDir string) string {
+	data, err := os.ReadFile(filepath.Join(configDir, "config.json"))
+	if err != nil {
+		return ""
+	}
+
+	var cfg struct {
+		CurrentContext string `json:"currentContext"`
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return ""
+	}
+
+	return cfg.CurrentContext
+}
+
+func loadContext(configDir, name string) (*Endpoint, error) {
+	sum := sha256.Sum256([]byte(name))
+	id := hex.EncodeToString(sum[:])
+
+	data, err := os.ReadFile(filepath.Join(configDir, "contexts", "meta", id, "meta.json"))
+	if err != nil {
+		return nil, errors.Wrap(ErrNoContext, name)
+	}
+
+	var meta contextMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, errors.Wrap(err, "failed to parse docker context")
+	}
+
+	ep := &Endpoint{Host: meta.Endpoints.Docker.Host}
+
+	tlsDir := filepath.Join(configDir, "contexts", "tls", id, "docker")
+	if _, err := os.Stat(filepath.Join(tlsDir, "key.pem")); err == nil {
+		ep.CACert = filepath.Join(tlsDir, "ca.pem")
+		ep.Cert = filepath.Join(tlsDir, "cert.pem")
+		ep.Key = filepath.Join(tlsDir, "key.pem")
+	}
+
+	return ep, nil
+}
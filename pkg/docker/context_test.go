@@ -0,0 +1,70 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeContext(t *testing.T, dir, name, host string) {
+	t.Helper()
+
+	sum := sha256.Sum256([]byte(name))
+	meta := filepath.Join(dir, "contexts", "meta", hex.EncodeToString(sum[:]))
+	if err := os.MkdirAll(meta, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	data := `{"Name":"` + name + `","Endpoints":{"docker":{"Host":"` + host + `"}}}`
+	if err := os.WriteFile(filepath.Join(meta, "meta.json"), []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveEndpoint(t *testing.T) {
+	dir := t.TempDir()
+	writeContext(t, dir, "remote", "tcp://remote:2376")
+	writeContext(t, dir, "other", "tcp://other:2376")
+	writeContext(t, dir, "tunnel", "ssh://user@remote")
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"currentContext":"remote"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOCKER_HOST", "")
+	t.Setenv("DOCKER_CONTEXT", "")
+
+	tests := []struct {
+		name        string
+		contextName string
+		host        string
+		env         string
+		want        string
+		wantErr     bool
+	}{
+		{name: "host", host: "unix:///tmp/docker.sock", want: "unix:///tmp/docker.sock"},
+		{name: "context", contextName: "other", want: "tcp://other:2376"},
+		{name: "current context", want: "tcp://remote:2376"},
+		{name: "default", contextName: "default", want: ""},
+		{name: "environment", env: "tcp://env:2376", want: ""},
+		{name: "unknown", contextName: "missing", wantErr: true},
+		{name: "ssh host", host: "ssh://user@remote", wantErr: true},
+		{name: "ssh context", contextName: "tunnel", wantErr: true},
+		{name: "ssh environment", env: "ssh://user@remote", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DOCKER_HOST", tt.env)
+			ep, err := ResolveEndpoint(dir, tt.contextName, tt.host)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveEndpoint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			got := ""
+			if ep != nil {
+				got = ep.Host
+			}
+			if got != tt.want {
+				t.Errorf("ResolveEndpoint() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -10,91 +10,143 @@ import (
 
 	"github.com/dimcz/viewer/internal/config"
 	"github.com/dimcz/viewer/pkg/logger"
+	"github.com/dimcz/viewer/pkg/source"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/pkg/errors"
 )
 
-type Container struct {
-	ID   string
-	Name string
-}
-
 type Docker struct {
-	containers []Container
-	current    int
-
 	cli *client.Client
 	log *logger.Logger
 	cfg *config.Config
+}
+
+func (d *Docker) Targets(ctx context.Context) ([]source.Target, error) {
+	list, err := d.cli.ContainerList(ctx, types.ContainerListOptions{})
+	if err != nil {
+		return nil, err
+	}
 
-	ctx    context.Context
-	cancel func()
+	targets := make([]source.Target, 0, len(list))
+
+	for _, c := range list {
+		name := strings.Join(c.Names, ", ")
+		if !d.selected(c.ID, name) {
+			continue
+		}
+
+		targets = append(targets, source.Target{
+			ID:      c.ID,
+			Name:    name,
+			Image:   c.Image,
+			Caption: fmt.Sprintf("%s (ID:%s)", strings.Replace(name, "/", "", 1), source.ShortID(c.ID)),
+			Labels:  c.Labels,
+		})
+	}
+
+	return targets, nil
 }
 
-func (d *Docker) Load(ctx context.Context, out io.Writer, tail int) {
-	d.ctx, d.cancel = context.WithCancel(ctx)
+// selected reports whether the container matches the containers given on the
+// command line by a part of the name or a prefix of the ID.
+func (d *Docker) selected(id, name string) bool {
+	if len(d.cfg.Containers) == 0 {
+		return true
+	}
+
+	for _, c := range d.cfg.Containers {
+		if strings.Contains(name, c) || strings.HasPrefix(id, c) {
+			return true
+		}
+	}
 
-	info, err := d.cli.ContainerInspect(d.ctx, d.containers[d.current].ID)
+	return false
+}
+
+func (d *Docker) Open(ctx context.Context, t source.Target, out io.Writer, o source.Options) error {
+	info, err := d.cli.ContainerInspect(ctx, t.ID)
 	if err != nil {
-		return
+		return err
 	}
 
 	opts := types.ContainerLogsOptions{
 		ShowStderr: true,
 		ShowStdout: true,
 		Timestamps: d.cfg.Timestamp,
-		Follow:     true,
+		Follow:     o.Follow,
 	}
 
-	if tail > 0 {
-		opts.Tail = strconv.Itoa(tail)
+	if o.Tail > 0 {
+		opts.Tail = strconv.Itoa(o.Tail)
 	}
 
-	go d.download(info.Config.Tty, out, opts)
-	time.Sleep(100 * time.Millisecond)
-}
+	if !o.Since.IsZero() {
+		opts.Since = o.Since.Format(time.RFC3339Nano)
+	}
 
-func (d *Docker) SetNextContainer() {
-	c := d.current + 1
-	if c >= len(d.containers) {
-		c = 0
+	if !o.Until.IsZero() {
+		opts.Until = o.Until.Format(time.RFC3339Nano)
 	}
 
-	d.current = c
+	return d.download(ctx, t.ID, info.Config.Tty, out, opts)
 }
 
-func (d *Docker) SetPrevContainer() {
-	c := d.current - 1
-	if c < 0 {
-		c = len(d.containers) - 1
+func (d *Docker) Metadata(t source.Target) []string {
+	return []string{
+		"container: " + strings.Replace(t.Name, "/", "", 1),
+		"id: " + t.ID,
+		"image: " + t.Image,
 	}
+}
 
-	d.current = c
+// Watch reports started and stopped containers from the docker events.
+func (d *Docker) Watch(ctx context.Context, changed func()) error {
+	msgs, errs := d.cli.Events(ctx, types.EventsOptions{
+		Filters: filters.NewArgs(filters.Arg("type", events.ContainerEventType)),
+	})
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			return err
+		case msg := <-msgs:
+			switch msg.Action {
+			case "start", "die", "destroy", "rename":
+				changed()
+			}
+		}
+	}
 }
 
-func (d *Docker) Name() string {
-	return fmt.Sprintf("(%d/%d) %s (ID:%s)",
-		d.current+1,
-		len(d.containers),
-		strings.Replace(d.containers[d.current].Name, "/", "", 1),
-		d.containers[d.current].ID[:12])
+func (d *Docker) Inspect(ctx context.Context, t source.Target) ([]byte, error) {
+	_, raw, err := d.cli.ContainerInspectWithRaw(ctx, t.ID, false)
+
+	return raw, err
 }
 
-func (d *Docker) Close() {
-	d.Stop()
+func (d *Docker) Top(ctx context.Context, t source.Target) (container.ContainerTopOKBody, error) {
+	return d.cli.ContainerTop(ctx, t.ID, nil)
+}
 
-	d.log.LogOnErr(d.cli.Close())
+func (d *Docker) Diff(ctx context.Context, t source.Target) ([]container.ContainerChangeResponseItem, error) {
+	return d.cli.ContainerDiff(ctx, t.ID)
 }
 
-func (d *Docker) Stop() {
-	d.cancel()
+func (d *Docker) Close() {
+	d.log.LogOnErr(d.cli.Close())
 }
 
-func (d *Docker) download(tty bool, out io.Writer, opts types.ContainerLogsOptions) {
-	fd, err := d.cli.ContainerLogs(d.ctx, d.containers[d.current].ID, opts)
+func (d *Docker) download(ctx context.Context, id string, tty bool, out io.Writer, opts types.ContainerLogsOptions) error {
+	fd, err := d.cli.ContainerLogs(ctx, id, opts)
 	if err != nil {
-		d.log.Error("failed to load logs:", err)
+		return errors.Wrap(err, "failed to load logs")
 	}
 
 	defer func() {
@@ -102,40 +154,46 @@ func (d *Docker) download(tty bool, out io.Writer, opts types.ContainerLogsOptio
 	}()
 
 	if tty {
-		_, _ = io.Copy(out, fd)
+		_, err = io.Copy(out, fd)
 	} else {
-		_, _ = stdcopy.StdCopy(out, out, fd)
+		_, err = stdcopy.StdCopy(out, out, fd)
+	}
+
+	if ctx.Err() != nil {
+		return nil
 	}
+
+	return err
 }
 
-func getContainers(cli *client.Client) (containers []Container, err error) {
-	list, err := cli.ContainerList(context.Background(), types.ContainerListOptions{})
+func Client(log *logger.Logger, cfg *config.Config) (*Docker, error) {
+	ep, err := ResolveEndpoint(ConfigDir(cfg.DockerConfig), cfg.DockerContext, cfg.DockerHost)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, c := range list {
-		containers = append(containers, Container{c.ID, strings.Join(c.Names, ", ")})
-	}
+	opts := []client.Opt{client.FromEnv, client.WithAPIVersionNegotiation()}
 
-	return containers, nil
-}
+	if ep != nil {
+		opts = append(opts, client.WithHost(ep.Host))
 
-func Client(log *logger.Logger, cfg *config.Config) (*Docker, error) {
-	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+		if ep.Key != "" {
+			opts = append(opts, client.WithTLSClientConfig(ep.CACert, ep.Cert, ep.Key))
+		}
+	}
+
+	cli, err := client.NewClientWithOpts(opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	containers, err := getContainers(cli)
-	if err != nil {
+	if _, err := cli.Ping(context.Background()); err != nil {
 		return nil, err
 	}
 
 	return &Docker{
-		log:        log,
-		cfg:        cfg,
-		cli:        cli,
-		containers: containers,
+		log: log,
+		cfg: cfg,
+		cli: cli,
 	}, nil
 }
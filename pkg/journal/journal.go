@@ -0,0 +1,137 @@
+package journal
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dimcz/viewer/pkg/logger"
+	"github.com/dimcz/viewer/pkg/source"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Follow reads the live journal through journalctl instead of a file.
+	Follow = "journalctl"
+
+	kind       = "journal"
+	lineLimit  = 100_000
+	readyDelay = 500 * time.Millisecond
+)
+
+// Journal splits journal entries into one target per systemd unit.
+type Journal struct {
+	*source.Streams
+
+	log    *logger.Logger
+	cancel func()
+	wg     sync.WaitGroup
+}
+
+// New reads the export or JSON file, or follows the local journal
+// when fileName is Follow.
+func New(log *logger.Logger, fileName string, tail int) (*Journal, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	j := &Journal{
+		Streams: source.NewStreams(kind, lineLimit),
+		log:     log,
+		cancel:  cancel,
+	}
+
+	r, err := j.input(ctx, fileName, tail)
+	if err != nil {
+		cancel()
+
+		return nil, err
+	}
+
+	ready := make(chan struct{})
+
+	var once sync.Once
+
+	j.wg.Add(1)
+
+	go func() {
+		defer j.wg.Done()
+		defer r.Close()
+		defer once.Do(func() { close(ready) })
+
+		err := Parse(r, func(e Entry) {
+			j.Append(e.Unit, e.Lines()...)
+			once.Do(func() { close(ready) })
+		})
+		if err != nil && ctx.Err() == nil {
+			log.Error("failed to read journal: ", err)
+		}
+	}()
+
+	// A saved file is read to the end, the live journal gets a moment
+	// to show its first units.
+	if fileName == Follow {
+		select {
+		case <-ready:
+			time.Sleep(readyDelay)
+		case <-time.After(readyDelay):
+		}
+	} else {
+		j.wg.Wait()
+	}
+
+	return j, nil
+}
+
+func (j *Journal) Metadata(t source.Target) []string {
+	return []string{"unit: " + t.Name}
+}
+
+func (j *Journal) Close() {
+	j.cancel()
+	j.wg.Wait()
+}
+
+func (j *Journal) input(ctx context.Context, fileName string, tail int) (io.ReadCloser, error) {
+	if fileName != Follow {
+		fd, err := os.Open(fileName)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open journal")
+		}
+
+		return fd, nil
+	}
+
+	args := []string{"-o", "export", "-f"}
+	if tail > 0 {
+		args = append(args, "-n", strconv.Itoa(tail))
+	}
+
+	cmd := exec.CommandContext(ctx, Follow, args...)
+
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Wrap(err, "failed to start journalctl")
+	}
+
+	return &cmdReader{ReadCloser: out, cmd: cmd}, nil
+}
+
+// cmdReader waits for journalctl once its output is read.
+type cmdReader struct {
+	io.ReadCloser
+
+	cmd *exec.Cmd
+}
+
+func (r *cmdReader) Close() error {
+	_ = r.cmd.Wait()
+
+	return nil
+}
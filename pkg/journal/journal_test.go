@@ -0,0 +1,37 @@
+package journal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dimcz/viewer/pkg/logger"
+)
+
+func TestNew_file(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "journal.export")
+	if err := os.WriteFile(fileName, []byte(testExport()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := New(&logger.Logger{}, fileName, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	targets, err := j.Targets(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, target := range targets {
+		names = append(names, target.Name)
+	}
+	if want := []string{"cron.service", "kernel", "nginx.service"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Targets() = %v, want %v", names, want)
+	}
+}
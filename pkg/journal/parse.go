@@ -0,0 +1,186 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dimcz/viewer/pkg/source"
+	"github.com/pkg/errors"
+)
+
+var ErrInvalidField = errors.New("invalid journal field")
+
+// Entry is a journal entry reduced to the fields dview shows.
+type Entry struct {
+	Unit     string
+	Time     time.Time
+	Priority int
+	Message  string
+}
+
+// Lines formats the entry with its priority as level.
+func (e Entry) Lines() []string {
+	return source.EntryLines(e.Time, e.Priority, e.Message)
+}
+
+// Parse reads the journal export or JSON format, detected from the first byte,
+// and calls fn for every entry.
+func Parse(r io.Reader, fn func(Entry)) error {
+	br := bufio.NewReader(r)
+
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		switch b[0] {
+		case '\n', '\r', ' ', '\t':
+			_, _ = br.ReadByte()
+		case '{':
+			return parseJSON(br, fn)
+		default:
+			return parseExport(br, fn)
+		}
+	}
+}
+
+func parseExport(br *bufio.Reader, fn func(Entry)) error {
+	fields := make(map[string]string)
+
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		eof := errors.Is(err, io.EOF)
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "":
+			if len(fields) > 0 {
+				fn(newEntry(fields))
+				fields = make(map[string]string)
+			}
+		case strings.Contains(line, "="):
+			i := strings.IndexByte(line, '=')
+			fields[line[:i]] = line[i+1:]
+		default:
+			// Binary fields, e.g. multi-line messages: the name, a 64-bit
+			// little endian size, the data and a newline.
+			data, err := readBinary(br)
+			if err != nil {
+				return err
+			}
+
+			fields[line] = data
+		}
+
+		if eof {
+			if len(fields) > 0 {
+				fn(newEntry(fields))
+			}
+
+			return nil
+		}
+	}
+}
+
+func readBinary(br *bufio.Reader) (string, error) {
+	var size uint64
+	if err := binary.Read(br, binary.LittleEndian, &size); err != nil {
+		return "", errors.Wrap(ErrInvalidField, err.Error())
+	}
+
+	data := make([]byte, size+1)
+	if _, err := io.ReadFull(br, data); err != nil {
+		return "", errors.Wrap(ErrInvalidField, err.Error())
+	}
+
+	return string(data[:size]), nil
+}
+
+func parseJSON(br *bufio.Reader, fn func(Entry)) error {
+	dec := json.NewDecoder(br)
+
+	for {
+		var raw map[string]json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		fields := make(map[string]string, len(raw))
+		for k, v := range raw {
+			fields[k] = jsonValue(v)
+		}
+
+		fn(newEntry(fields))
+	}
+}
+
+// jsonValue decodes a JSON field, which is a string, or an array
+// of bytes for binary values, or null.
+func jsonValue(v json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(v, &s); err == nil {
+		return s
+	}
+
+	var list []int
+	if err := json.Unmarshal(v, &list); err == nil {
+		b := make([]byte, len(list))
+		for i, c := range list {
+			b[i] = byte(c)
+		}
+
+		return string(b)
+	}
+
+	return ""
+}
+
+func newEntry(fields map[string]string) Entry {
+	e := Entry{
+		Unit:     unitName(fields),
+		Priority: source.SeverityInfo,
+		Message:  fields["MESSAGE"],
+	}
+
+	if p, err := strconv.Atoi(fields["PRIORITY"]); err == nil {
+		e.Priority = p
+	}
+
+	if us, err := strconv.ParseInt(fields["__REALTIME_TIMESTAMP"], 10, 64); err == nil {
+		e.Time = time.UnixMicro(us)
+	}
+
+	return e
+}
+
+func unitName(fields map[string]string) string {
+	for _, key := range []string{"_SYSTEMD_UNIT", "_SYSTEMD_USER_UNIT", "SYSLOG_IDENTIFIER", "_COMM"} {
+		if fields[key] != "" {
+			return fields[key]
+		}
+	}
+
+	if fields["_TRANSPORT"] == "kernel" {
+		return "kernel"
+	}
+
+	return "unknown"
+}
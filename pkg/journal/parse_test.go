@@ -0,0 +1,76 @@
+package journal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func exportBinary(name, value string) string {
+	var b bytes.Buffer
+	b.WriteString(name + "\n")
+	_ = binary.Write(&b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value + "\n")
+	return b.String()
+}
+
+func testExport() string {
+	return "__REALTIME_TIMESTAMP=1000000\n_SYSTEMD_UNIT=nginx.service\nPRIORITY=3\nMESSAGE=failed\n\n" +
+		"__REALTIME_TIMESTAMP=2000000\n_SYSTEMD_UNIT=cron.service\n" +
+		exportBinary("MESSAGE", "first\nsecond") +
+		"\n" +
+		"__REALTIME_TIMESTAMP=3000000\n_TRANSPORT=kernel\nPRIORITY=7\nMESSAGE=tick\n"
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Entry
+	}{
+		{
+			name:  "export",
+			input: testExport(),
+			want: []Entry{
+				{Unit: "nginx.service", Time: time.UnixMicro(1000000), Priority: 3, Message: "failed"},
+				{Unit: "cron.service", Time: time.UnixMicro(2000000), Priority: 6, Message: "first\nsecond"},
+				{Unit: "kernel", Time: time.UnixMicro(3000000), Priority: 7, Message: "tick"},
+			},
+		},
+		{
+			name: "json",
+			input: `{"__REALTIME_TIMESTAMP":"1000000","_SYSTEMD_UNIT":"nginx.service","PRIORITY":"4","MESSAGE":"slow"}
+{"__REALTIME_TIMESTAMP":"2000000","SYSLOG_IDENTIFIER":"sshd","MESSAGE":[104,105,10,116,104,101,114,101]}
+`,
+			want: []Entry{
+				{Unit: "nginx.service", Time: time.UnixMicro(1000000), Priority: 4, Message: "slow"},
+				{Unit: "sshd", Time: time.UnixMicro(2000000), Priority: 6, Message: "hi\nthere"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []Entry
+			if err := Parse(strings.NewReader(tt.input), func(e Entry) { got = append(got, e) }); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEntry_Lines(t *testing.T) {
+	e := Entry{Time: time.UnixMicro(0).UTC(), Priority: 3, Message: "first\nsecond\n"}
+	want := []string{
+		"1970-01-01T00:00:00.000000Z ERROR first",
+		"                                  second",
+	}
+	if got := e.Lines(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,247 @@
+package kube
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrNoContext        = errors.New("kubeconfig context not found")
+	ErrNoCluster        = errors.New("kubeconfig cluster not found")
+	ErrExecNotSupported = errors.New("kubeconfig exec credentials are not supported")
+)
+
+type kubeConfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Contexts       []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster   string `yaml:"cluster"`
+			User      string `yaml:"user"`
+			Namespace string `yaml:"namespace"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+	Clusters []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string      `yaml:"token"`
+			TokenFile             string      `yaml:"tokenFile"`
+			ClientCertificate     string      `yaml:"client-certificate"`
+			ClientCertificateData string      `yaml:"client-certificate-data"`
+			ClientKey             string      `yaml:"client-key"`
+			ClientKeyData         string      `yaml:"client-key-data"`
+			Username              string      `yaml:"username"`
+			Password              string      `yaml:"password"`
+			Exec                  interface{} `yaml:"exec"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+}
+
+// Config is the resolved connection of a kubeconfig context.
+type Config struct {
+	Server    string
+	Namespace string
+
+	token    string
+	username string
+	password string
+	tls      *tls.Config
+}
+
+// DefaultConfigPath returns $KUBECONFIG (its first entry) or ~/.kube/config.
+func DefaultConfigPath() string {
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		return filepath.SplitList(env)[0]
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".kube", "config")
+}
+
+// LoadConfig reads the kubeconfig and resolves the context,
+// the current context when name is empty.
+func LoadConfig(fileName, name string) (*Config, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read kubeconfig")
+	}
+
+	var kc kubeConfig
+	if err := yaml.Unmarshal(data, &kc); err != nil {
+		return nil, errors.Wrap(err, "failed to parse kubeconfig")
+	}
+
+	if name == "" {
+		name = kc.CurrentContext
+	}
+
+	cfg := &Config{tls: &tls.Config{MinVersion: tls.VersionTLS12}}
+	base := filepath.Dir(fileName)
+
+	var clusterName, userName string
+
+	found := false
+
+	for _, c := range kc.Contexts {
+		if c.Name == name {
+			clusterName, userName, cfg.Namespace = c.Context.Cluster, c.Context.User, c.Context.Namespace
+			found = true
+
+			break
+		}
+	}
+
+	if !found {
+		return nil, errors.Wrap(ErrNoContext, name)
+	}
+
+	found = false
+
+	for _, c := range kc.Clusters {
+		if c.Name != clusterName {
+			continue
+		}
+
+		found = true
+		cfg.Server = strings.TrimSuffix(c.Cluster.Server, "/")
+		cfg.tls.InsecureSkipVerify = c.Cluster.InsecureSkipTLSVerify //nolint:gosec
+
+		ca, err := fileOrData(base, c.Cluster.CertificateAuthority, c.Cluster.CertificateAuthorityData)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read certificate authority")
+		}
+
+		if ca != nil {
+			pool := x509.NewCertPool()
+			pool.AppendCertsFromPEM(ca)
+			cfg.tls.RootCAs = pool
+		}
+
+		break
+	}
+
+	if !found {
+		return nil, errors.Wrap(ErrNoCluster, clusterName)
+	}
+
+	for _, u := range kc.Users {
+		if u.Name != userName {
+			continue
+		}
+
+		if u.User.Exec != nil && u.User.Token == "" && u.User.TokenFile == "" {
+			return nil, ErrExecNotSupported
+		}
+
+		if err := cfg.setUser(base, u.User.Token, u.User.TokenFile); err != nil {
+			return nil, err
+		}
+
+		cfg.username, cfg.password = u.User.Username, u.User.Password
+
+		cert, err := fileOrData(base, u.User.ClientCertificate, u.User.ClientCertificateData)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read client certificate")
+		}
+
+		key, err := fileOrData(base, u.User.ClientKey, u.User.ClientKeyData)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read client key")
+		}
+
+		if cert != nil && key != nil {
+			pair, err := tls.X509KeyPair(cert, key)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to load client certificate")
+			}
+
+			cfg.tls.Certificates = []tls.Certificate{pair}
+		}
+
+		break
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) setUser(base, token, tokenFile string) error {
+	if token != "" || tokenFile == "" {
+		c.token = token
+
+		return nil
+	}
+
+	data, err := fileOrData(base, tokenFile, "")
+	if err != nil {
+		return errors.Wrap(err, "failed to read token file")
+	}
+
+	c.token = strings.TrimSpace(string(data))
+
+	return nil
+}
+
+// Client returns an http client which authenticates every request.
+func (c *Config) Client() *http.Client {
+	return &http.Client{
+		Transport: &authTransport{
+			cfg:  c,
+			next: &http.Transport{TLSClientConfig: c.tls, Proxy: http.ProxyFromEnvironment},
+		},
+	}
+}
+
+type authTransport struct {
+	cfg  *Config
+	next http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+
+	switch {
+	case t.cfg.token != "":
+		req.Header.Set("Authorization", "Bearer "+t.cfg.token)
+	case t.cfg.username != "":
+		req.SetBasicAuth(t.cfg.username, t.cfg.password)
+	}
+
+	return t.next.RoundTrip(req)
+}
+
+func fileOrData(base, fileName, data string) ([]byte, error) {
+	if data != "" {
+		return base64.StdEncoding.DecodeString(data)
+	}
+
+	if fileName == "" {
+		return nil, nil
+	}
+
+	if !filepath.IsAbs(fileName) {
+		fileName = filepath.Join(base, fileName)
+	}
+
+	return os.ReadFile(fileName)
+}
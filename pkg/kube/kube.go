@@ -0,0 +1,309 @@
+package kube
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dimcz/viewer/pkg/logger"
+	"github.com/dimcz/viewer/pkg/source"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultNamespace = "default"
+	previousSuffix   = " (previous)"
+	retryInterval    = 5 * time.Second
+)
+
+var ErrStatus = errors.New("unexpected status")
+
+type pod struct {
+	Metadata struct {
+		Name      string            `json:"name"`
+		Namespace string            `json:"namespace"`
+		Labels    map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Spec struct {
+		NodeName   string `json:"nodeName"`
+		Containers []struct {
+			Name  string `json:"name"`
+			Image string `json:"image"`
+		} `json:"containers"`
+	} `json:"spec"`
+	Status struct {
+		ContainerStatuses []struct {
+			Name         string `json:"name"`
+			RestartCount int    `json:"restartCount"`
+			LastState    struct {
+				Terminated *struct {
+					ExitCode int    `json:"exitCode"`
+					Reason   string `json:"reason"`
+				} `json:"terminated"`
+			} `json:"lastState"`
+		} `json:"containerStatuses"`
+	} `json:"status"`
+}
+
+type podList struct {
+	Items []pod `json:"items"`
+}
+
+// Kube streams the logs of pod containers from the Kubernetes API.
+type Kube struct {
+	server    string
+	namespace string
+	selector  string
+	timestamp bool
+
+	cli *http.Client
+	log *logger.Logger
+}
+
+func New(log *logger.Logger, cfg *Config, namespace, selector string, timestamp bool) *Kube {
+	if namespace == "" {
+		namespace = cfg.Namespace
+	}
+
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+
+	return &Kube{
+		server:    cfg.Server,
+		namespace: namespace,
+		selector:  selector,
+		timestamp: timestamp,
+		cli:       cfg.Client(),
+		log:       log,
+	}
+}
+
+// Targets lists every container of the pods. Containers which were restarted
+// after termination also get a target with the logs of the previous instance.
+func (k *Kube) Targets(ctx context.Context) ([]source.Target, error) {
+	var list podList
+	if err := k.getJSON(ctx, k.podsPath(), nil, &list); err != nil {
+		return nil, err
+	}
+
+	var targets []source.Target
+
+	for _, p := range list.Items {
+		for _, c := range p.Spec.Containers {
+			t := source.Target{
+				ID:      path(p.Metadata.Namespace, p.Metadata.Name, c.Name),
+				Name:    p.Metadata.Name + "/" + c.Name,
+				Image:   c.Image,
+				Caption: path(p.Metadata.Namespace, p.Metadata.Name, c.Name),
+				Labels:  p.Metadata.Labels,
+			}
+
+			targets = append(targets, t)
+
+			if crashed(p, c.Name) {
+				t.ID += previousSuffix
+				t.Name += previousSuffix
+				t.Caption += previousSuffix
+				targets = append(targets, t)
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+func (k *Kube) Open(ctx context.Context, t source.Target, out io.Writer, opts source.Options) error {
+	ns, name, container, previous := parseID(t.ID)
+
+	query := url.Values{"container": {container}}
+	if previous {
+		query.Set("previous", "true")
+	} else if opts.Follow {
+		query.Set("follow", "true")
+	}
+
+	if opts.Tail > 0 {
+		query.Set("tailLines", strconv.Itoa(opts.Tail))
+	}
+
+	if !opts.Since.IsZero() {
+		query.Set("sinceTime", opts.Since.UTC().Format(time.RFC3339))
+	}
+
+	if k.timestamp {
+		query.Set("timestamps", "true")
+	}
+
+	resp, err := k.get(ctx, "/api/v1/namespaces/"+ns+"/pods/"+name+"/log", query)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		k.log.LogOnErr(resp.Body.Close())
+	}()
+
+	// The API has no until, lines with timestamps are filtered.
+	_, err = io.Copy(source.TimeFilter(out, source.Options{Until: opts.Until}), resp.Body)
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return err
+}
+
+func (k *Kube) Metadata(t source.Target) []string {
+	ns, name, container, previous := parseID(t.ID)
+
+	meta := []string{
+		"namespace: " + ns,
+		"pod: " + name,
+		"container: " + container,
+		"image: " + t.Image,
+	}
+
+	if previous {
+		meta = append(meta, "previous: true")
+	}
+
+	return meta
+}
+
+// Watch follows the pod watch stream and reconnects when the server closes it.
+func (k *Kube) Watch(ctx context.Context, changed func()) error {
+	for {
+		if err := k.watch(ctx, changed); err != nil {
+			k.log.Error("pod watch: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+func (k *Kube) Inspect(ctx context.Context, t source.Target) ([]byte, error) {
+	ns, name, _, _ := parseID(t.ID)
+
+	resp, err := k.get(ctx, "/api/v1/namespaces/"+ns+"/pods/"+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
+func (k *Kube) watch(ctx context.Context, changed func()) error {
+	resp, err := k.get(ctx, k.podsPath(), url.Values{"watch": {"true"}})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	for scanner.Scan() {
+		var event struct {
+			Type string `json:"type"`
+		}
+
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+
+		switch event.Type {
+		case "ADDED", "MODIFIED", "DELETED":
+			changed()
+		}
+	}
+
+	return scanner.Err()
+}
+
+func (k *Kube) podsPath() string {
+	return "/api/v1/namespaces/" + k.namespace + "/pods"
+}
+
+func (k *Kube) getJSON(ctx context.Context, p string, query url.Values, v interface{}) error {
+	resp, err := k.get(ctx, p, query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (k *Kube) get(ctx context.Context, p string, query url.Values) (*http.Response, error) {
+	if k.selector != "" && strings.HasSuffix(p, "/pods") {
+		if query == nil {
+			query = url.Values{}
+		}
+
+		query.Set("labelSelector", k.selector)
+	}
+
+	u := k.server + p
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := k.cli.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		_ = resp.Body.Close()
+
+		return nil, errors.Wrap(ErrStatus, fmt.Sprintf("%s: %s", resp.Status, strings.TrimSpace(string(body))))
+	}
+
+	return resp, nil
+}
+
+func crashed(p pod, container string) bool {
+	for _, s := range p.Status.ContainerStatuses {
+		if s.Name == container {
+			return s.RestartCount > 0 && s.LastState.Terminated != nil
+		}
+	}
+
+	return false
+}
+
+func path(ns, name, container string) string {
+	return ns + "/" + name + "/" + container
+}
+
+func parseID(id string) (ns, name, container string, previous bool) {
+	if strings.HasSuffix(id, previousSuffix) {
+		id = strings.TrimSuffix(id, previousSuffix)
+		previous = true
+	}
+
+	parts := strings.SplitN(id, "/", 3)
+	for len(parts) < 3 {
+		parts = append(parts, "")
+	}
+
+	return parts[0], parts[1], parts[2], previous
+}
@@ -0,0 +1,148 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dimcz/viewer/pkg/logger"
+	"github.com/dimcz/viewer/pkg/source"
+)
+
+const testPods = `{"items":[{
+	"metadata":{"name":"web-1","namespace":"shop"},
+	"spec":{"containers":[{"name":"app","image":"web:1"},{"name":"proxy","image":"envoy"}]},
+	"status":{"containerStatuses":[
+		{"name":"app","restartCount":2,"lastState":{"terminated":{"exitCode":1,"reason":"Error"}}},
+		{"name":"proxy","restartCount":0,"lastState":{}}
+	]}
+}]}`
+
+func fakeServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/namespaces/shop/pods":
+			if r.URL.Query().Get("labelSelector") != "app=web" {
+				t.Errorf("labelSelector = %q", r.URL.Query().Get("labelSelector"))
+			}
+			fmt.Fprint(w, testPods)
+		case "/api/v1/namespaces/shop/pods/web-1/log":
+			q := r.URL.Query()
+			fmt.Fprintf(w, "container=%s previous=%s follow=%s tailLines=%s\n",
+				q.Get("container"), q.Get("previous"), q.Get("follow"), q.Get("tailLines"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func testKube(t *testing.T, server string) *Kube {
+	t.Helper()
+
+	fileName := filepath.Join(t.TempDir(), "config")
+	config := fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: test
+contexts:
+- name: test
+  context:
+    cluster: fake
+    user: admin
+    namespace: shop
+clusters:
+- name: fake
+  cluster:
+    server: %s
+users:
+- name: admin
+  user:
+    token: secret
+`, server)
+	if err := os.WriteFile(fileName, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(fileName, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return New(&logger.Logger{}, cfg, "", "app=web", false)
+}
+
+func TestKube_Targets(t *testing.T) {
+	ts := fakeServer(t)
+	defer ts.Close()
+
+	k := testKube(t, ts.URL)
+
+	targets, err := k.Targets(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"shop/web-1/app", "shop/web-1/app (previous)", "shop/web-1/proxy"}
+	if len(targets) != len(want) {
+		t.Fatalf("Targets() = %v, want %v", targets, want)
+	}
+	for i, w := range want {
+		if targets[i].ID != w {
+			t.Errorf("Targets()[%d] = %v, want %v", i, targets[i].ID, w)
+		}
+	}
+}
+
+func TestKube_Open(t *testing.T) {
+	ts := fakeServer(t)
+	defer ts.Close()
+
+	k := testKube(t, ts.URL)
+
+	tests := []struct {
+		name string
+		id   string
+		want string
+	}{
+		{
+			name: "follow",
+			id:   "shop/web-1/app",
+			want: "container=app previous= follow=true tailLines=10\n",
+		},
+		{
+			name: "previous",
+			id:   "shop/web-1/app (previous)",
+			want: "container=app previous=true follow= tailLines=10\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targets, err := k.Targets(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, target := range targets {
+				if target.ID != tt.id {
+					continue
+				}
+				var buf bytes.Buffer
+				if err := k.Open(context.Background(), target, &buf, source.Options{Tail: 10, Follow: true}); err != nil {
+					t.Fatal(err)
+				}
+				if got := buf.String(); got != tt.want {
+					t.Errorf("Open() = %q, want %q", got, tt.want)
+				}
+			}
+		})
+	}
+}
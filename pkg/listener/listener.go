@@ -0,0 +1,290 @@
+package listener
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dimcz/viewer/pkg/logger"
+	"github.com/dimcz/viewer/pkg/source"
+	"github.com/pkg/errors"
+)
+
+const (
+	SplitHost = "hostname"
+	SplitApp  = "app-name"
+
+	kind       = "listen"
+	lineLimit  = 100_000
+	packetSize = 64 * 1024
+	maxFrame   = 1024 * 1024
+)
+
+var (
+	ErrUnknownScheme = errors.New("unknown listen scheme, use udp:// or tcp://")
+	ErrUnknownSplit  = errors.New("unknown split, use hostname or app-name")
+)
+
+// Listener receives syslog or raw lines over UDP and TCP and splits
+// them by hostname or app-name into targets which grow live.
+type Listener struct {
+	*source.Streams
+
+	split string
+	log   *logger.Logger
+
+	mu        sync.Mutex
+	addrs     []string
+	closers   []io.Closer
+	wg        sync.WaitGroup
+	closed    bool
+	listeners []string
+}
+
+func New(log *logger.Logger, addrs []string, split string) (*Listener, error) {
+	if split != SplitHost && split != SplitApp {
+		return nil, errors.Wrap(ErrUnknownSplit, split)
+	}
+
+	l := &Listener{
+		Streams: source.NewStreams(kind, lineLimit),
+		split:   split,
+		log:     log,
+	}
+
+	for _, addr := range addrs {
+		if err := l.listen(addr); err != nil {
+			l.Close()
+
+			return nil, err
+		}
+	}
+
+	return l, nil
+}
+
+// Addrs returns the bound addresses, e.g. to learn a random port.
+func (l *Listener) Addrs() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]string(nil), l.addrs...)
+}
+
+func (l *Listener) Metadata(t source.Target) []string {
+	return []string{
+		l.split + ": " + t.Name,
+		"listen: " + strings.Join(l.listeners, ", "),
+	}
+}
+
+func (l *Listener) Close() {
+	l.mu.Lock()
+	l.closed = true
+
+	for _, c := range l.closers {
+		l.log.LogOnErr(c.Close())
+	}
+	l.mu.Unlock()
+
+	l.wg.Wait()
+}
+
+func (l *Listener) listen(addr string) error {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return errors.Wrap(err, "failed to parse listen address")
+	}
+
+	switch u.Scheme {
+	case "udp":
+		conn, err := net.ListenPacket("udp", u.Host)
+		if err != nil {
+			return errors.Wrap(err, "failed to listen")
+		}
+
+		l.add(addr, "udp://"+conn.LocalAddr().String(), conn)
+		l.serve(func() { l.readPackets(conn) })
+	case "tcp":
+		ln, err := net.Listen("tcp", u.Host)
+		if err != nil {
+			return errors.Wrap(err, "failed to listen")
+		}
+
+		l.add(addr, "tcp://"+ln.Addr().String(), ln)
+		l.serve(func() { l.accept(ln) })
+	default:
+		return errors.Wrap(ErrUnknownScheme, addr)
+	}
+
+	return nil
+}
+
+func (l *Listener) add(addr, bound string, c io.Closer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.listeners = append(l.listeners, addr)
+	l.addrs = append(l.addrs, bound)
+	l.closers = append(l.closers, c)
+}
+
+func (l *Listener) serve(fn func()) {
+	l.wg.Add(1)
+
+	go func() {
+		defer l.wg.Done()
+
+		fn()
+	}()
+}
+
+func (l *Listener) readPackets(conn net.PacketConn) {
+	buf := make([]byte, packetSize)
+
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			l.logErr(err)
+
+			return
+		}
+
+		// A datagram is one message, a stack trace keeps its lines.
+		l.receive(strings.TrimRight(string(buf[:n]), "\r\n"), host(addr))
+	}
+}
+
+func (l *Listener) accept(ln net.Listener) {
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			l.logErr(err)
+
+			return
+		}
+
+		if !l.track(conn) {
+			return
+		}
+
+		l.serve(func() { l.readStream(conn) })
+	}
+}
+
+// track keeps the connection to close it with the listener.
+func (l *Listener) track(conn net.Conn) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		_ = conn.Close()
+
+		return false
+	}
+
+	l.closers = append(l.closers, conn)
+
+	return true
+}
+
+// readStream reads newline-delimited or octet-counted (RFC 6587) frames.
+func (l *Listener) readStream(conn net.Conn) {
+	defer conn.Close()
+
+	sender := host(conn.RemoteAddr())
+	br := bufio.NewReader(conn)
+
+	for {
+		frame, err := readFrame(br)
+		if frame != "" {
+			l.receive(frame, sender)
+		}
+
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				l.logErr(err)
+			}
+
+			return
+		}
+	}
+}
+
+func readFrame(br *bufio.Reader) (string, error) {
+	b, err := br.Peek(1)
+	if err != nil {
+		return "", err
+	}
+
+	if b[0] >= '1' && b[0] <= '9' {
+		if size, ok := frameSize(br); ok {
+			data := make([]byte, size)
+			_, err := io.ReadFull(br, data)
+
+			return strings.TrimRight(string(data), "\r\n"), err
+		}
+	}
+
+	line, err := br.ReadString('\n')
+
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+// frameSize consumes the "SIZE " prefix of an octet-counted frame.
+func frameSize(br *bufio.Reader) (int, bool) {
+	peek, _ := br.Peek(8)
+
+	i := strings.IndexByte(string(peek), ' ')
+	if i < 1 || len(peek) <= i+1 || peek[i+1] != '<' {
+		return 0, false
+	}
+
+	size, err := strconv.Atoi(string(peek[:i]))
+	if err != nil || size > maxFrame {
+		return 0, false
+	}
+
+	_, _ = br.Discard(i + 1)
+
+	return size, true
+}
+
+func (l *Listener) receive(data, sender string) {
+	if data == "" {
+		return
+	}
+
+	m := Parse(data, sender, time.Now())
+
+	name := m.Hostname
+	if l.split == SplitApp && m.AppName != "" {
+		name = m.AppName
+	}
+
+	l.Append(name, source.EntryLines(m.Time, m.Severity, m.Text)...)
+}
+
+func (l *Listener) logErr(err error) {
+	l.mu.Lock()
+	closed := l.closed
+	l.mu.Unlock()
+
+	if !closed {
+		l.log.Error("listener: ", err)
+	}
+}
+
+func host(addr net.Addr) string {
+	h, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+
+	return h
+}
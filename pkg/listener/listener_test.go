@@ -0,0 +1,130 @@
+package listener
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dimcz/viewer/pkg/logger"
+)
+
+func waitTargets(t *testing.T, l *Listener, want int) []string {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		targets, err := l.Targets(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(targets) >= want || time.Now().After(deadline) {
+			names := make([]string, 0, len(targets))
+			for _, target := range targets {
+				names = append(names, target.Name)
+			}
+			return names
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestListener(t *testing.T) {
+	tests := []struct {
+		name  string
+		split string
+		send  []string
+		want  []string
+	}{
+		{
+			name:  "hostname",
+			split: SplitHost,
+			send: []string{
+				"<34>Jun 30 22:14:15 router sshd: login failed\n",
+				"<165>1 2022-06-30T22:14:15.003Z web01 nginx - - - request failed\n",
+			},
+			want: []string{"router", "web01"},
+		},
+		{
+			name:  "app-name",
+			split: SplitApp,
+			send: []string{
+				"<34>Jun 30 22:14:15 router sshd: login failed\n",
+				"<165>1 2022-06-30T22:14:15.003Z web01 nginx - - - request failed\n",
+			},
+			want: []string{"nginx", "sshd"},
+		},
+	}
+	for _, tt := range tests {
+		for _, network := range []string{"udp", "tcp"} {
+			t.Run(tt.name+"/"+network, func(t *testing.T) {
+				l, err := New(&logger.Logger{}, []string{network + "://127.0.0.1:0"}, tt.split)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer l.Close()
+
+				addr := strings.TrimPrefix(l.Addrs()[0], network+"://")
+				for _, msg := range tt.send {
+					conn, err := net.Dial(network, addr)
+					if err != nil {
+						t.Fatal(err)
+					}
+					fmt.Fprint(conn, msg)
+					conn.Close()
+				}
+
+				got := waitTargets(t, l, len(tt.want))
+				if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+					t.Errorf("Targets() = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestListener_multilineDatagram(t *testing.T) {
+	l, err := New(&logger.Logger{}, []string{"udp://127.0.0.1:0"}, SplitApp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	conn, err := net.Dial("udp", strings.TrimPrefix(l.Addrs()[0], "udp://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprint(conn, "<34>Jun 30 22:14:15 router app: panic\n\tat main.go:10\n")
+	conn.Close()
+
+	if got := waitTargets(t, l, 1); len(got) != 1 || got[0] != "app" {
+		t.Fatalf("Targets() = %v, want [app]", got)
+	}
+	// A continuation line would add the sender stream.
+	time.Sleep(50 * time.Millisecond)
+	if got := waitTargets(t, l, 2); len(got) != 1 {
+		t.Errorf("Targets() = %v, want [app]", got)
+	}
+}
+
+func TestListener_octetCounting(t *testing.T) {
+	l, err := New(&logger.Logger{}, []string{"tcp://127.0.0.1:0"}, SplitApp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(l.Addrs()[0], "tcp://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := "<34>Jun 30 22:14:15 router sshd: a\nb"
+	fmt.Fprintf(conn, "%d %s", len(msg), msg)
+	conn.Close()
+
+	if got := waitTargets(t, l, 1); len(got) != 1 || got[0] != "sshd" {
+		t.Errorf("Targets() = %v, want [sshd]", got)
+	}
+}
@@ -0,0 +1,162 @@
+package listener
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dimcz/viewer/pkg/source"
+)
+
+const (
+	rfc3164Layout = "Jan _2 15:04:05"
+	nilValue      = "-"
+)
+
+// Message is a received syslog message or a raw line.
+type Message struct {
+	Time     time.Time
+	Severity int
+	Hostname string
+	AppName  string
+	Text     string
+}
+
+// Parse parses RFC5424 and RFC3164 messages. Anything else is kept
+// as raw text from the sender host.
+func Parse(data, sender string, now time.Time) Message {
+	m := Message{Time: now, Severity: source.SeverityInfo, Hostname: sender, Text: data}
+
+	pri, rest, ok := priority(data)
+	if !ok {
+		return m
+	}
+
+	m.Severity = pri % 8
+
+	if strings.HasPrefix(rest, "1 ") {
+		parse5424(&m, rest[2:], now)
+	} else {
+		parse3164(&m, rest, now)
+	}
+
+	return m
+}
+
+func priority(data string) (int, string, bool) {
+	if !strings.HasPrefix(data, "<") {
+		return 0, "", false
+	}
+
+	end := strings.IndexByte(data, '>')
+	if end < 2 || end > 4 {
+		return 0, "", false
+	}
+
+	pri, err := strconv.Atoi(data[1:end])
+	if err != nil || pri > 191 {
+		return 0, "", false
+	}
+
+	return pri, data[end+1:], true
+}
+
+// parse5424 parses TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG.
+func parse5424(m *Message, rest string, now time.Time) {
+	fields := strings.SplitN(rest, " ", 6)
+	if len(fields) < 6 {
+		m.Text = rest
+
+		return
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, fields[0]); err == nil {
+		m.Time = t
+	} else {
+		m.Time = now
+	}
+
+	if fields[1] != nilValue {
+		m.Hostname = fields[1]
+	}
+
+	if fields[2] != nilValue {
+		m.AppName = fields[2]
+	}
+
+	sd, msg := structuredData(fields[5])
+	msg = strings.TrimPrefix(msg, "\ufeff")
+
+	if sd != nilValue && sd != "" {
+		msg = sd + " " + msg
+	}
+
+	m.Text = strings.TrimSpace(msg)
+}
+
+// structuredData splits the structured data elements from the message.
+func structuredData(s string) (string, string) {
+	if strings.HasPrefix(s, nilValue) {
+		return nilValue, strings.TrimPrefix(s[1:], " ")
+	}
+
+	inValue, escaped, depth := false, false, 0
+
+	for i, c := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '"':
+			inValue = !inValue
+		case inValue:
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == ' ' && depth == 0:
+			return s[:i], s[i+1:]
+		}
+	}
+
+	return s, ""
+}
+
+// parse3164 parses TIMESTAMP HOSTNAME TAG: MSG.
+func parse3164(m *Message, rest string, now time.Time) {
+	m.Text = rest
+
+	if len(rest) < len(rfc3164Layout)+1 {
+		return
+	}
+
+	t, err := time.ParseInLocation(rfc3164Layout, rest[:len(rfc3164Layout)], now.Location())
+	if err != nil {
+		return
+	}
+
+	m.Time = time.Date(now.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, now.Location())
+	if m.Time.After(now.AddDate(0, 1, 0)) {
+		m.Time = m.Time.AddDate(-1, 0, 0)
+	}
+
+	rest = strings.TrimPrefix(rest[len(rfc3164Layout):], " ")
+
+	if i := strings.IndexByte(rest, ' '); i > 0 {
+		m.Hostname, rest = rest[:i], rest[i+1:]
+	}
+
+	m.Text = rest
+
+	// The tag ends with ':' or '[pid]:'.
+	if i := strings.IndexByte(rest, ':'); i > 0 && !strings.ContainsAny(rest[:i], " ") {
+		tag := rest[:i]
+		if j := strings.IndexByte(tag, '['); j > 0 {
+			tag = tag[:j]
+		}
+
+		m.AppName = tag
+		m.Text = strings.TrimPrefix(rest[i+1:], " ")
+	}
+}
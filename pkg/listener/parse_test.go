@@ -0,0 +1,60 @@
+package listener
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		data string
+		want Message
+	}{
+		{
+			name: "rfc5424",
+			data: `<165>1 2022-06-30T22:14:15.003Z web01 nginx 42 ID47 [exampleSDID@32473 iut="3" eventSource="App"] request failed`,
+			want: Message{
+				Time:     time.Date(2022, 6, 30, 22, 14, 15, 3000000, time.UTC),
+				Severity: 5,
+				Hostname: "web01",
+				AppName:  "nginx",
+				Text:     `[exampleSDID@32473 iut="3" eventSource="App"] request failed`,
+			},
+		},
+		{
+			name: "rfc5424 nil values",
+			data: "<11>1 - - - - - - disk full",
+			want: Message{Time: now, Severity: 3, Hostname: "10.0.0.1", Text: "disk full"},
+		},
+		{
+			name: "rfc3164",
+			data: "<34>Jun 30 22:14:15 router sshd[123]: login failed",
+			want: Message{
+				Time:     time.Date(2022, 6, 30, 22, 14, 15, 0, time.UTC),
+				Severity: 2,
+				Hostname: "router",
+				AppName:  "sshd",
+				Text:     "login failed",
+			},
+		},
+		{
+			name: "raw",
+			data: "plain text line",
+			want: Message{Time: now, Severity: 6, Hostname: "10.0.0.1", Text: "plain text line"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.data, "10.0.0.1", now)
+			if !got.Time.Equal(tt.want.Time) {
+				t.Errorf("Parse() time = %v, want %v", got.Time, tt.want.Time)
+			}
+			got.Time = tt.want.Time
+			if got != tt.want {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
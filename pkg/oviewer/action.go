@@ -7,6 +7,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,6 +24,10 @@ func (root *Root) toggleWrapMode() {
 //  toggleColumnMode toggles ColumnMode each time it is called.
 func (root *Root) toggleColumnMode() {
 	root.Doc.ColumnMode = !root.Doc.ColumnMode
+	if root.Doc.ColumnDelimiter == LogfmtDelimiter {
+		root.Doc.resetLogfmt()
+		root.Doc.ClearCache()
+	}
 	root.setMessagef("Set ColumnMode %t", root.Doc.ColumnMode)
 }
 
@@ -78,6 +83,11 @@ func (root *Root) reload(m *Document) {
 		return
 	}
 
+	if m.generate != nil {
+		root.regenerate(m)
+		return
+	}
+
 	if err := m.reload(); err != nil {
 		root.log("cannot reload: ", err)
 		return
@@ -87,6 +97,37 @@ func (root *Root) reload(m *Document) {
 	time.Sleep(100 * time.Millisecond)
 }
 
+// regenerate runs the generate function of a generated document
+// in the background, so a slow generator does not block the event loop.
+// The output is posted back to replace the lines.
+func (root *Root) regenerate(m *Document) {
+	if !atomic.CompareAndSwapInt32(&m.generating, 0, 1) {
+		return
+	}
+	go func() {
+		ev := &eventGenerated{}
+		ev.m = m
+		ev.lines, ev.err = m.generateLines()
+		ev.SetEventNow()
+		if err := root.Screen.PostEvent(ev); err != nil {
+			root.log(err)
+			atomic.StoreInt32(&m.generating, 0)
+		}
+	}()
+}
+
+// setGenerated replaces the lines of a generated document.
+// The old lines are kept if generate failed.
+func (root *Root) setGenerated(m *Document, lines []string, err error) {
+	atomic.StoreInt32(&m.generating, 0)
+	if err != nil {
+		root.setMessagef("cannot reload: %s", err)
+		root.log("cannot reload: ", err)
+		return
+	}
+	m.setLines(lines)
+}
+
 // toggleWatch toggles watch mode.
 func (root *Root) toggleWatch() {
 	if root.Doc.WatchMode {
@@ -130,6 +171,11 @@ func (root *Root) watchStart() {
 
 // goLine will move to the specified line.
 func (root *Root) goLine(input string) {
+	if isGotoTime(input) {
+		root.goTime(input)
+		return
+	}
+
 	if !strings.Contains(input, ".") {
 		// Line number only.
 		lN, err := strconv.Atoi(input)
@@ -302,27 +348,43 @@ func (root *Root) toggleMouse() {
 	}
 }
 
-// setViewMode switches to the preset display mode.
-// Set header lines and columMode together.
+// setViewMode switches to the preset display mode chosen by hand.
 func (root *Root) setViewMode(input string) {
+	if root.applyViewMode(input) {
+		root.filterParent().viewMode = input
+	}
+}
+
+// applyViewMode switches to the preset display mode.
+// Set header lines and columMode together.
+// It returns false if there is no such mode.
+func (root *Root) applyViewMode(input string) bool {
 	c, ok := root.Config.Mode[input]
 	if !ok {
 		if input != "general" {
 			root.setMessagef("%s mode not found", input)
-			return
+			return false
 		}
 		c = root.General
 	}
 
-	root.Doc.general = overwriteGeneral(root.Doc.general, c)
-	root.Doc.setSectionDelimiter(root.Doc.SectionDelimiter)
-	root.Doc.ClearCache()
+	m := root.filterParent()
+	m.general = overwriteGeneral(m.general, c)
+	m.setSectionDelimiter(m.SectionDelimiter)
+	m.ClearCache()
+	root.applyFilters()
 	root.ViewSync()
 	root.setMessagef("Set mode %s", input)
+	return true
 }
 
 // setDelimiter sets the delimiter string.
 func (root *Root) setDelimiter(input string) {
+	if root.Doc.ColumnDelimiter == LogfmtDelimiter || input == LogfmtDelimiter {
+		root.Doc.resetLogfmt()
+		root.Doc.ClearCache()
+		root.Doc.columnNum = 0
+	}
 	root.Doc.ColumnDelimiter = input
 	root.setMessagef("Set delimiter %s", input)
 }
@@ -449,7 +511,7 @@ func (root *Root) tailSection() {
 func (root *Root) prepareStartX() {
 	root.startX = 0
 	if root.Doc.LineNumMode {
-		root.startX = len(fmt.Sprintf("%d", root.Doc.BufEndNum())) + 1
+		root.startX = len(fmt.Sprintf("%d", root.Doc.lastLineNum())) + 1
 	}
 }
 
@@ -0,0 +1,67 @@
+package oviewer
+
+import (
+	"hash/fnv"
+)
+
+// LineAnchor returns the hash of the contents of a line.
+// Marks are kept as anchors between loads, because the same line
+// gets another line number when the logs are read again.
+func LineAnchor(line string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(line))
+	return h.Sum64()
+}
+
+// MarkAnchors returns the anchors of the marked lines.
+func (m *Document) MarkAnchors() []uint64 {
+	anchors := make([]uint64, 0, len(m.marked))
+	for _, lN := range m.marked {
+		if lN < m.BufEndNum() {
+			anchors = append(anchors, LineAnchor(m.GetLine(lN)))
+		}
+	}
+	return anchors
+}
+
+// SetMarkAnchors marks the lines with the anchors, the ones read already
+// and the ones read later. Each anchor marks the first line it matches.
+func (m *Document) SetMarkAnchors(anchors []uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.anchors = make(map[uint64]bool, len(anchors))
+	for _, a := range anchors {
+		m.anchors[a] = true
+	}
+	for lN := 0; lN < m.endNum; lN++ {
+		m.anchorLine(lN)
+	}
+}
+
+// anchorLine marks the line if it matches a pending anchor.
+// m.mu must be held.
+func (m *Document) anchorLine(lN int) {
+	if len(m.anchors) == 0 {
+		return
+	}
+	a := LineAnchor(m.lines[lN])
+	if !m.anchors[a] {
+		return
+	}
+	delete(m.anchors, a)
+	m.anchored = append(m.anchored, lN)
+}
+
+// takeAnchored adds the lines marked by anchors to the marks.
+// It is called in the event loop, which owns the marks.
+func (m *Document) takeAnchored() {
+	m.mu.Lock()
+	anchored := m.anchored
+	m.anchored = nil
+	m.mu.Unlock()
+	for _, lN := range anchored {
+		if !containsInt(m.marked, lN) {
+			m.marked = append(m.marked, lN)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package oviewer
+
+import (
+	"compress/gzip"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+// CompressType returns the compression format from the extension of the file name.
+// Only the formats that can also be written are returned.
+func CompressType(fileName string) Compressed {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".gz":
+		return GZIP
+	case ".zst":
+		return ZSTD
+	case ".xz":
+		return XZ
+	}
+	return UNCOMPRESSED
+}
+
+// nopWriteCloser is a WriteCloser whose Close does nothing.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close does nothing.
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// CompressedWriter returns a writer that compresses in the specified format.
+// Closing the returned writer flushes the compressed stream,
+// but does not close w.
+func CompressedWriter(cFormat Compressed, w io.Writer) (io.WriteCloser, error) {
+	switch cFormat {
+	case GZIP:
+		return gzip.NewWriter(w), nil
+	case ZSTD:
+		return zstd.NewWriter(w)
+	case XZ:
+		return xz.NewWriter(w)
+	}
+	return nopWriteCloser{w}, nil
+}
@@ -0,0 +1,200 @@
+package oviewer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/mattn/go-runewidth"
+)
+
+// detailField is a field of the line detail with the rows it is shown on.
+type detailField struct {
+	key   string
+	value string
+	start int
+	end   int
+}
+
+// lineDetail returns the rows of the line detail and the fields on them.
+// A JSON line is pretty-printed, the pairs of a logfmt line are one per row
+// and the escaped newlines and tabs of the values are expanded.
+func lineDetail(line string) ([]string, []detailField) {
+	plain := stripEscapeSequence(line)
+	if prefix, fields, ok := parseJSONLine(plain); ok {
+		return jsonDetail(prefix, fields)
+	}
+	if cells, ok := parseLogfmt(plain); ok {
+		return logfmtDetail(cells)
+	}
+	rows := strings.Split(expandEscapes(line, ""), "\n")
+	return rows, []detailField{{key: "line", value: plain, start: 0, end: len(rows) - 1}}
+}
+
+// jsonDetail returns the rows of a pretty-printed JSON line.
+func jsonDetail(prefix string, fields []jsonField) ([]string, []detailField) {
+	var rows []string
+	if p := strings.TrimSpace(prefix); p != "" {
+		rows = append(rows, p)
+	}
+	rows = append(rows, "{")
+
+	details := make([]detailField, 0, len(fields))
+	for i, f := range fields {
+		var b bytes.Buffer
+		value := string(f.value)
+		if err := json.Indent(&b, f.value, "  ", "  "); err == nil {
+			value = b.String()
+		}
+		text := fmt.Sprintf("  %q: %s", f.key, expandEscapes(value, "    "))
+		if i < len(fields)-1 {
+			text += ","
+		}
+
+		start := len(rows)
+		rows = append(rows, strings.Split(text, "\n")...)
+		details = append(details, detailField{
+			key:   f.key,
+			value: jsonValue("", f.value),
+			start: start,
+			end:   len(rows) - 1,
+		})
+	}
+	rows = append(rows, "}")
+	return rows, details
+}
+
+// logfmtDetail returns the rows of the pairs of a logfmt line, one per row.
+func logfmtDetail(cells []logfmtCell) ([]string, []detailField) {
+	width := 0
+	for _, c := range cells {
+		width = max(width, runewidth.StringWidth(c.key))
+	}
+
+	var rows []string
+	details := make([]detailField, 0, len(cells))
+	indent := strings.Repeat(" ", width+2)
+	for _, c := range cells {
+		value := c.text
+		if c.key != "" {
+			value = strings.TrimPrefix(value, c.key+"=")
+		}
+		text := expandEscapes(value, indent)
+		if s, err := strconv.Unquote(value); c.key != "" && err == nil {
+			value = s
+			text = strings.ReplaceAll(s, "\n", "\n"+indent)
+		}
+		text = fmt.Sprintf("%-*s  %s", width, c.key, text)
+
+		start := len(rows)
+		rows = append(rows, strings.Split(text, "\n")...)
+		details = append(details, detailField{
+			key:   c.key,
+			value: value,
+			start: start,
+			end:   len(rows) - 1,
+		})
+	}
+	return rows, details
+}
+
+// expandEscapes replaces the escaped newlines with newlines followed by
+// the indent, and the escaped tabs with tabs.
+func expandEscapes(s string, indent string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteString("\n" + indent)
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// lineDetailDisplay switches between the line detail of the current line
+// and the normal screen.
+func (root *Root) lineDetailDisplay() {
+	if root.screenMode == Detail {
+		root.toNormal()
+		return
+	}
+	root.openLineDetail(root.Doc.topLN + root.Doc.firstLine())
+}
+
+// lineDetailAt displays the line detail of the line at y on the screen.
+func (root *Root) lineDetailAt(y int) {
+	if y < root.headerLen || y >= root.vHight-statusLine || y >= len(root.lnumber) {
+		return
+	}
+	root.openLineDetail(root.lnumber[y].line)
+}
+
+// openLineDetail displays the line detail of the line of the document.
+func (root *Root) openLineDetail(lN int) {
+	if root.screenMode != Docs {
+		root.setMessage("Line detail is only available for documents")
+		return
+	}
+	m := root.Doc
+	if lN < 0 || lN >= m.BufEndNum() {
+		return
+	}
+
+	rows, fields := lineDetail(m.GetLine(lN))
+	doc, err := NewDocument()
+	if err != nil {
+		root.setMessagef("line detail: %s", err)
+		return
+	}
+	doc.append(rows...)
+	doc.FileName = "Line detail"
+	doc.Caption = fmt.Sprintf("[detail] %s:%d", m.Caption, m.originLN(lN)-m.firstLine()+1)
+	doc.eof = 1
+	doc.preventReload = true
+	doc.seekable = false
+
+	root.detailFields = fields
+	root.setDocument(doc)
+	root.screenMode = Detail
+}
+
+// copyField copies the value of the field at the current line
+// of the line detail to the clipboard.
+func (root *Root) copyField() {
+	if root.screenMode != Detail {
+		root.setMessage("Open the line detail to copy a field")
+		return
+	}
+
+	lN := root.Doc.topLN + root.Doc.firstLine()
+	for _, f := range root.detailFields {
+		if lN < f.start || lN > f.end {
+			continue
+		}
+		if err := clipboard.WriteAll(f.value); err != nil {
+			root.setMessagef("copy: %s", err)
+			return
+		}
+		root.setMessagef("Copy %s", f.key)
+		return
+	}
+	root.setMessage("No field at the current line")
+}
@@ -0,0 +1,91 @@
+package oviewer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_lineDetail(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		wantRows   []string
+		wantFields []detailField
+	}{
+		{
+			name: "json",
+			line: `{"level":"error","msg":"failed\nretry","ctx":{"id":1}}`,
+			wantRows: []string{
+				`{`,
+				`  "level": "error",`,
+				`  "msg": "failed`,
+				`    retry",`,
+				`  "ctx": {`,
+				`    "id": 1`,
+				`  }`,
+				`}`,
+			},
+			wantFields: []detailField{
+				{key: "level", value: "error", start: 1, end: 1},
+				{key: "msg", value: "failed\nretry", start: 2, end: 3},
+				{key: "ctx", value: `{"id":1}`, start: 4, end: 6},
+			},
+		},
+		{
+			name: "logfmt",
+			line: `ts=10:00 msg="a b\nc" level=info`,
+			wantRows: []string{
+				"ts     10:00",
+				"msg    a b",
+				"       c",
+				"level  info",
+			},
+			wantFields: []detailField{
+				{key: "ts", value: "10:00", start: 0, end: 0},
+				{key: "msg", value: "a b\nc", start: 1, end: 2},
+				{key: "level", value: "info", start: 3, end: 3},
+			},
+		},
+		{
+			name:     "plain",
+			line:     `panic: boom\n\tmain.go:10`,
+			wantRows: []string{"panic: boom", "\tmain.go:10"},
+			wantFields: []detailField{
+				{key: "line", value: `panic: boom\n\tmain.go:10`, start: 0, end: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, fields := lineDetail(tt.line)
+			if !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("lineDetail() rows = %q, want %q", rows, tt.wantRows)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("lineDetail() fields = %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func Test_expandEscapes(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      string
+		indent string
+		want   string
+	}{
+		{name: "none", s: "abc", want: "abc"},
+		{name: "newline", s: `a\nb`, indent: "  ", want: "a\n  b"},
+		{name: "tab", s: `a\tb`, want: "a\tb"},
+		{name: "carriage return", s: `a\r\nb`, want: "a\nb"},
+		{name: "quote", s: `a\"b\\`, want: `a\"b\\`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandEscapes(tt.s, tt.indent); got != tt.want {
+				t.Errorf("expandEscapes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
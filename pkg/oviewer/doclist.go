@@ -21,6 +21,9 @@ func (root *Root) hasDocChanged() bool {
 		if atomic.SwapInt32(&doc.changed, 0) == 1 {
 			eventFlag = true
 		}
+		if doc.filterDoc != nil && atomic.SwapInt32(&doc.filterDoc.changed, 0) == 1 {
+			eventFlag = true
+		}
 	}
 	return eventFlag
 }
@@ -31,6 +34,7 @@ func (root *Root) replaceDocument(m *Document) {
 
 	root.mu.Lock()
 	root.DocList = append(root.DocList, m)
+	root.DocList[root.CurrentDoc].stopFilter()
 	if err := root.DocList[root.CurrentDoc].close(); err != nil {
 		root.log("%s:%s", root.Doc.FileName, err)
 	}
@@ -45,6 +49,7 @@ func (root *Root) replaceDocument(m *Document) {
 		root.debugMessage(fmt.Sprintf("watcher %s:%s", m.Caption, err))
 	}
 	root.setDocument(m)
+	root.applyFilters()
 }
 
 // addDocument adds a document and displays it.
@@ -72,6 +77,7 @@ func (root *Root) closeDocument() {
 	root.setMessagef("close [%d]%s", root.CurrentDoc, root.Doc.Caption)
 	root.log("close [%d]%s", root.CurrentDoc, root.Doc.FileName)
 	root.mu.Lock()
+	root.DocList[root.CurrentDoc].stopFilter()
 	if err := root.DocList[root.CurrentDoc].close(); err != nil {
 		root.log("%s:%s", root.Doc.FileName, err)
 	}
@@ -123,7 +129,11 @@ func (root *Root) setDocumentNum(docNum int) {
 }
 
 // setDocument sets the Document.
+// A filtered document is shown by its filtered view.
 func (root *Root) setDocument(m *Document) {
+	if m.filterDoc != nil {
+		m = m.filterDoc
+	}
 	root.Doc = m
 	if m.WatchMode {
 		root.watchStart()
@@ -151,8 +161,28 @@ func (root *Root) logDisplay() {
 	root.screenMode = LogDoc
 }
 
+// panelDisplay displays the panel document instead of the normal screen.
+func (root *Root) panelDisplay(m *Document) {
+	root.closePanel()
+	root.panelDoc = m
+	root.setDocument(m)
+	root.screenMode = Panel
+}
+
+// closePanel stops the watch of the panel document and releases it.
+func (root *Root) closePanel() {
+	if root.panelDoc == nil {
+		return
+	}
+	root.panelDoc.WatchMode = false
+	root.panelDoc = nil
+}
+
 // toNormal displays a normal document.
 func (root *Root) toNormal() {
+	root.closePanel()
+	root.detailFields = nil
+
 	root.mu.RLock()
 	m := root.DocList[root.CurrentDoc]
 	root.mu.RUnlock()
@@ -21,6 +21,8 @@ type Document struct {
 	FileName string
 	// Caption is an additional caption to display after the file name.
 	Caption string
+	// Metadata is information about the document written to the header of the saved file.
+	Metadata []string
 
 	// File is the os.File.
 	file *os.File
@@ -33,6 +35,11 @@ type Document struct {
 	preventReload bool
 	// Is it possible to seek.
 	seekable bool
+	// generate writes the contents of the document.
+	// It is called again on reload.
+	generate func(w io.Writer) error
+	// generating is 1 while generate runs in the background.
+	generating int32
 
 	// lines stores the contents of the file in slices of strings.
 	// lines,endNum and eof is updated by reader goroutine.
@@ -67,6 +74,38 @@ type Document struct {
 
 	// status is the display status of the document.
 	general
+	// viewMode is the name of the mode chosen by hand last.
+	viewMode string
+
+	// parent is the document a filtered document shows the lines of.
+	parent *Document
+	// filterDoc is the filtered view of the document, nil if not filtered.
+	filterDoc *Document
+	// levelMu protects levels and levelCount.
+	levelMu sync.Mutex
+	// levels is the level of each classified line.
+	levels []Level
+	// levelCount is the number of lines of each level.
+	levelCount [levelNum]int
+	// levelLast is the last classified line with a level.
+	levelLast int
+
+	// jsonWidths are the widths of the columns of the JSON mode.
+	jsonWidths []int
+	// logfmtKeys are the keys of the logfmt columns in the order they were seen.
+	logfmtKeys []string
+	// logfmtWidths are the widths of the logfmt columns.
+	logfmtWidths []int
+	// columnWidened is true if a JSON or logfmt column was widened while drawing.
+	columnWidened bool
+	// timeLoc is the location of timeLocZone.
+	timeLoc     *time.Location
+	timeLocZone string
+
+	// filterCancel stops filtering the lines of the parent.
+	filterCancel context.CancelFunc
+	// lineNum is the line number in the parent of each filtered line.
+	lineNum []int
 
 	// WatchMode is watch mode.
 	WatchMode bool
@@ -91,6 +130,10 @@ type Document struct {
 	// marked is a list of marked line numbers.
 	marked      []int
 	markedPoint int
+	// anchors are the marks waiting for their lines to be read,
+	// anchored the lines found for them. Both are guarded by mu.
+	anchors  map[uint64]bool
+	anchored []int
 
 	// Last moved Section position.
 	lastSectionPosNum int
@@ -166,6 +209,26 @@ func STDINDocument() (*Document, error) {
 	return m, nil
 }
 
+// GenerateDocument returns a Document whose contents are written by generate.
+// The contents are replaced with a new output of generate on every reload,
+// so in watch mode the document is refreshed at each interval.
+func GenerateDocument(generate func(w io.Writer) error) (*Document, error) {
+	m, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+
+	m.seekable = false
+	m.generate = generate
+	lines, err := m.generateLines()
+	if err != nil {
+		return nil, err
+	}
+	m.append(lines...)
+	atomic.StoreInt32(&m.eof, 1)
+	return m, nil
+}
+
 func (m *Document) SetLog(log func(argv ...interface{})) {
 	m.log = log
 }
@@ -186,6 +249,38 @@ func (m *Document) CurrentLN() int {
 	return m.topLN
 }
 
+// Marks returns the marked line numbers.
+func (m *Document) Marks() []int {
+	marked := make([]int, len(m.marked))
+	copy(marked, m.marked)
+	return marked
+}
+
+// ViewMode returns the name of the mode chosen by hand last,
+// or "" if none was chosen.
+func (m *Document) ViewMode() string {
+	return m.viewMode
+}
+
+// General returns the view settings of the document.
+func (m *Document) General() General {
+	return m.general
+}
+
+// SetGeneral sets the view settings of the document.
+// Call it in the event loop, e.g. with Root.Call.
+func (m *Document) SetGeneral(g General) {
+	m.general = g
+	m.setSectionDelimiter(m.SectionDelimiter)
+	m.ClearCache()
+}
+
+// SetMarks sets the marked line numbers.
+func (m *Document) SetMarks(marked []int) {
+	m.marked = append(m.marked[:0], marked...)
+	m.markedPoint = 0
+}
+
 // Export exports the document in the specified range.
 func (m *Document) Export(w io.Writer, start int, end int) {
 	for n := start; n <= end; n++ {
@@ -245,6 +340,15 @@ func (m *Document) contentsLN(lN int, tabWidth int) (contents, error) {
 
 	// It wasn't cached.
 	str := m.GetLine(lN)
+	switch {
+	case m.JSONMode:
+		str = m.renderJSON(str)
+	case m.logfmtMode():
+		str = m.renderLogfmt(str)
+	}
+	if m.TimeMode != TimeOriginal {
+		str = m.renderTime(lN, str)
+	}
 	lc := parseString(str, tabWidth)
 	m.cache.Set(key, lc, 1)
 	return lc, nil
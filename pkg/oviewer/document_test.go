@@ -3,6 +3,8 @@ package oviewer
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"io"
 	"reflect"
 	"testing"
 )
@@ -320,3 +322,74 @@ func TestDocument_backSearchLine(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateDocument_reload(t *testing.T) {
+	count := 0
+	generate := func(w io.Writer) error {
+		count++
+		_, err := fmt.Fprintf(w, "TITLE\nrun %d\n", count)
+		return err
+	}
+	tests := []struct {
+		name     string
+		reloads  int
+		wantLine string
+		wantNum  int
+	}{
+		{
+			name:     "first",
+			reloads:  0,
+			wantLine: "run 1",
+			wantNum:  2,
+		},
+		{
+			name:     "reload",
+			reloads:  2,
+			wantLine: "run 3",
+			wantNum:  2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count = 0
+			m, err := GenerateDocument(generate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < tt.reloads; i++ {
+				if err := m.reload(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := m.BufEndNum(); got != tt.wantNum {
+				t.Errorf("Document.BufEndNum() = %v, want %v", got, tt.wantNum)
+			}
+			if got := m.GetLine(1); got != tt.wantLine {
+				t.Errorf("Document.GetLine() = %v, want %v", got, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestDocument_SetMarkAnchors(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.append("start", "error", "retry")
+	m.marked = []int{1, 2}
+	anchors := m.MarkAnchors()
+
+	// The logs are read again from another line.
+	reloaded, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded.append("boot", "start")
+	reloaded.SetMarkAnchors(anchors)
+	reloaded.append("error", "other", "retry")
+	reloaded.takeAnchored()
+	if got, want := reloaded.Marks(), []int{2, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Document.Marks() = %v, want %v", got, want)
+	}
+}
@@ -13,6 +13,7 @@ const statusLine = 1
 // draw is the main routine that draws the screen.
 func (root *Root) draw() {
 	m := root.Doc
+	m.takeAnchored()
 
 	if root.vHight == 0 {
 		m.topLN = 0
@@ -37,6 +38,12 @@ func (root *Root) draw() {
 	m.bottomLN = max(lY, 0)
 	m.bottomLX = lX
 
+	// The relative times are rendered again on the next draw.
+	if m.columnWidened || m.TimeMode == TimeRelative {
+		m.columnWidened = false
+		m.ClearCache()
+	}
+
 	if root.mouseSelect {
 		root.drawSelect(root.x1, root.y1, root.x2, root.y2, true)
 	}
@@ -70,7 +77,7 @@ func (root *Root) drawHeader() int {
 			wrap: wrapNum,
 		}
 
-		root.columnHighlight(lc, lineStr, posCV)
+		root.columnHighlight(lY, lc, lineStr, posCV)
 		root.blankLineNumber(hy)
 
 		lX, lY = root.drawLine(hy, lX, lY, lc)
@@ -107,6 +114,8 @@ func (root *Root) drawBody(lX int, lY int) (int, int) {
 			lc = m.getContents(lY, m.TabWidth)
 			lineStr, posCV = m.getContentsStr(lY, lc)
 			root.bodyStyle(lc, root.StyleBody)
+			root.levelStyle(lY, lc)
+			root.userHighlight(lY, lc, lineStr, posCV)
 			lastLN = lY
 		}
 
@@ -115,7 +124,7 @@ func (root *Root) drawBody(lX int, lY int) (int, int) {
 			wrap: wrapNum,
 		}
 
-		root.columnHighlight(lc, lineStr, posCV)
+		root.columnHighlight(lY, lc, lineStr, posCV)
 		root.searchHighlight(lY, lc, lineStr, posCV)
 		root.drawLineNumber(lY, y)
 
@@ -125,6 +134,9 @@ func (root *Root) drawBody(lX int, lY int) (int, int) {
 		root.alternateRowsStyle(currentY, y)
 		root.markStyle(currentY, y, markStyleWidth)
 		root.sectionLineHighlight(y, lineStr)
+		if lY != currentY {
+			root.gapHighlight(lY, y)
+		}
 
 		if lX > 0 {
 			wrapNum++
@@ -233,7 +245,7 @@ func (root *Root) drawLineNumber(lY int, y int) {
 		return
 	}
 	// Line numbers start at 1 except for skip and header lines.
-	numC := StrToContents(fmt.Sprintf("%*d", root.startX-1, lY-m.firstLine()+1), m.TabWidth)
+	numC := StrToContents(fmt.Sprintf("%*d", root.startX-1, m.originLN(lY)-m.firstLine()+1), m.TabWidth)
 	for i := 0; i < len(numC); i++ {
 		numC[i].style = applyStyle(tcell.StyleDefault, root.StyleLineNumber)
 	}
@@ -241,10 +253,18 @@ func (root *Root) drawLineNumber(lY int, y int) {
 }
 
 // columnHighlight applies the style of the column highlight.
-func (root *Root) columnHighlight(lc contents, str string, posCV map[int]int) {
+func (root *Root) columnHighlight(lY int, lc contents, str string, posCV map[int]int) {
 	if !root.Doc.ColumnMode {
 		return
 	}
+	if root.Doc.logfmtMode() {
+		if !root.Doc.JSONMode && root.Doc.isLogfmtLine(lY) {
+			if start, end, _, ok := root.Doc.logfmtRange(root.Doc.columnNum); ok {
+				RangeStyle(lc, min(start, len(lc)), min(end, len(lc)), root.StyleColumnHighlight)
+			}
+		}
+		return
+	}
 	start, end := rangePosition(str, root.Doc.ColumnDelimiter, root.Doc.columnNum)
 	RangeStyle(lc, posCV[start], posCV[end], root.StyleColumnHighlight)
 }
@@ -315,6 +335,12 @@ func (root *Root) normalLeftStatus() (contents, int) {
 	if root.Doc.FollowMode {
 		modeStatus = "(Follow Mode) "
 	}
+	if root.Doc.parent != nil {
+		modeStatus += fmt.Sprintf("(Filter %s) ", root.Doc.parent.filterStatus())
+	}
+	if levels := root.Doc.levelStatus(); levels != "" {
+		modeStatus += fmt.Sprintf("(%s) ", levels)
+	}
 	if root.General.FollowAll {
 		modeStatus = "(Follow All) "
 	}
@@ -356,6 +382,9 @@ func (root *Root) inputLeftStatus() (contents, int) {
 		}
 	}
 	p := searchMode + input.EventInput.Prompt()
+	if input.reverse != nil {
+		p = input.reverse.prompt()
+	}
 	leftStatus := p + input.value
 	leftContents := StrToContents(leftStatus, -1)
 	return leftContents, len(p) + input.cursorX
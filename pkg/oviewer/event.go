@@ -41,6 +41,8 @@ func (root *Root) main(ctx context.Context, quitChan chan<- struct{}) {
 			return
 		case *eventReload:
 			root.reload(ev.m)
+		case *eventGenerated:
+			root.setGenerated(ev.m, ev.lines, ev.err)
 		case *eventAppSuspend:
 			root.suspend()
 		case *eventUpdateEndNum:
@@ -53,6 +55,10 @@ func (root *Root) main(ctx context.Context, quitChan chan<- struct{}) {
 			root.replaceDocument(ev.m)
 		case *eventCloseDocument:
 			root.closeDocument()
+		case *eventDisplayPanel:
+			root.panelDisplay(ev.m)
+		case *eventCall:
+			ev.fn()
 		case *eventCopySelect:
 			root.putClipboard(ctx)
 		case *eventPaste:
@@ -65,6 +71,8 @@ func (root *Root) main(ctx context.Context, quitChan chan<- struct{}) {
 			root.searchMove(ctx, false, root.Doc.topLN+root.Doc.firstLine()-1, searcher)
 		case *viewModeInput:
 			root.setViewMode(ev.value)
+		case *eventViewMode:
+			root.applyViewMode(ev.mode)
 		case *searchInput:
 			searcher := root.setSearcher(root.input.value, root.CaseSensitive)
 			root.searchMove(ctx, true, root.Doc.topLN+root.Doc.firstLine(), searcher)
@@ -89,6 +97,18 @@ func (root *Root) main(ctx context.Context, quitChan chan<- struct{}) {
 			root.setSectionDelimiter(ev.value)
 		case *sectionStartInput:
 			root.setSectionStart(ev.value)
+		case *saveInput:
+			root.saveAs(ev.value)
+		case *filterInput:
+			root.filter(ev.value)
+		case *filterRulesInput:
+			root.filterRules(ev.value)
+		case *highlightInput:
+			root.highlight(ev.value)
+		case *jsonFieldsInput:
+			root.setJSONFields(ev.value)
+		case *gapThresholdInput:
+			root.setGapThreshold(ev.value)
 		case *tcell.EventResize:
 			root.resize()
 		case *tcell.EventMouse:
@@ -177,6 +197,9 @@ func (root *Root) follow() {
 	}
 
 	root.Doc.onceFollowMode()
+	if root.Doc.parent != nil {
+		root.Doc.parent.onceFollowMode()
+	}
 
 	num := root.Doc.BufEndNum()
 	if root.Doc.latestNum == num {
@@ -395,6 +418,70 @@ func (root *Root) AddDocument(m *Document) {
 	}
 }
 
+// eventDisplayPanel represents a display panel event.
+type eventDisplayPanel struct {
+	m *Document
+	tcell.EventTime
+}
+
+// DisplayPanel fires a display panel event.
+// The panel is displayed instead of the documents until quit is pressed.
+func (root *Root) DisplayPanel(m *Document) {
+	if !root.checkScreen() {
+		return
+	}
+	ev := &eventDisplayPanel{}
+	ev.m = m
+	ev.SetEventNow()
+	err := root.Screen.PostEvent(ev)
+	if err != nil {
+		root.log(err)
+	}
+}
+
+// eventCall represents a function call event.
+type eventCall struct {
+	fn func()
+	tcell.EventTime
+}
+
+// Call fires a function call event.
+// fn runs in the event loop like a key handler does.
+func (root *Root) Call(fn func()) {
+	if !root.checkScreen() {
+		return
+	}
+	ev := &eventCall{}
+	ev.fn = fn
+	ev.SetEventNow()
+	err := root.Screen.PostEvent(ev)
+	if err != nil {
+		root.log(err)
+	}
+}
+
+// eventViewMode represents a view mode event.
+type eventViewMode struct {
+	mode string
+	tcell.EventTime
+}
+
+// SetViewMode fires a view mode event.
+// The mode is applied to the document displayed at that time,
+// but unlike a mode chosen by hand it is not returned by ViewMode.
+func (root *Root) SetViewMode(mode string) {
+	if !root.checkScreen() {
+		return
+	}
+	ev := &eventViewMode{}
+	ev.mode = mode
+	ev.SetEventNow()
+	err := root.Screen.PostEvent(ev)
+	if err != nil {
+		root.log(err)
+	}
+}
+
 // eventCloseDocument represents a close document event.
 type eventCloseDocument struct {
 	tcell.EventTime
@@ -488,6 +575,14 @@ func (root *Root) Reload() {
 	}
 }
 
+// eventGenerated represents the output of a generated document.
+type eventGenerated struct {
+	m     *Document
+	lines []string
+	err   error
+	tcell.EventTime
+}
+
 // releaseEventBuffer will release all event buffers.
 func (root *Root) releaseEventBuffer() {
 	for root.HasPendingEvent() {
@@ -1,6 +1,9 @@
 package oviewer
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"path/filepath"
 	"testing"
 
@@ -48,3 +51,61 @@ func TestRoot_Search(t *testing.T) {
 		})
 	}
 }
+
+func TestRoot_regenerate(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	count := 0
+	fail := false
+	generate := func(w io.Writer) error {
+		if fail {
+			return errors.New("docker is gone")
+		}
+		count++
+		_, err := fmt.Fprintf(w, "TITLE\nrun %d\n", count)
+		return err
+	}
+	m, err := GenerateDocument(generate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := NewOviewer(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		fail     bool
+		wantLine string
+	}{
+		{
+			name:     "reload",
+			fail:     false,
+			wantLine: "run 2",
+		},
+		{
+			name:     "failed reload keeps lines",
+			fail:     true,
+			wantLine: "run 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fail = tt.fail
+			root.reload(m)
+			ev, ok := root.Screen.PollEvent().(*eventGenerated)
+			if !ok {
+				t.Fatal("reload did not post the generated lines")
+			}
+			root.setGenerated(ev.m, ev.lines, ev.err)
+			if got := m.BufEndNum(); got != 2 {
+				t.Errorf("Document.BufEndNum() = %v, want %v", got, 2)
+			}
+			if got := m.GetLine(1); got != tt.wantLine {
+				t.Errorf("Document.GetLine() = %v, want %v", got, tt.wantLine)
+			}
+		})
+	}
+}
@@ -0,0 +1,512 @@
+package oviewer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// filterChunk is the number of lines filtered between context checks.
+const filterChunk = 1000
+
+// FilterRule is a rule of the filter stack of a document.
+// Lines matching an include rule are shown and lines matching
+// an exclude rule are hidden.
+type FilterRule struct {
+	// Pattern is matched like the search word.
+	Pattern string
+	// Exclude hides the matching lines.
+	Exclude bool
+	// Context is the number of lines shown around a match of an include
+	// rule, or hidden after a match of an exclude rule (e.g. a stack trace).
+	Context int
+	// Disabled keeps the rule in the stack without applying it.
+	Disabled bool
+}
+
+// String returns the rule as it is entered in the filter input,
+// with the context lines as in grep.
+func (r FilterRule) String() string {
+	s := r.Pattern
+	if r.Exclude {
+		s = "!" + s
+	}
+	if r.Context > 0 {
+		s += " -C" + strconv.Itoa(r.Context)
+	}
+	return s
+}
+
+// ParseFilterRule returns the rule of a filter input.
+// A pattern starting with "!" excludes the matching lines.
+func ParseFilterRule(s string) FilterRule {
+	if strings.HasPrefix(s, "!") && len(s) > 1 {
+		return FilterRule{Pattern: s[1:], Exclude: true}
+	}
+	return FilterRule{Pattern: s}
+}
+
+// activeFilters returns the enabled rules.
+func activeFilters(rules []FilterRule) []FilterRule {
+	var active []FilterRule
+	for _, r := range rules {
+		if !r.Disabled {
+			active = append(active, r)
+		}
+	}
+	return active
+}
+
+// filterMatcher is a rule with its searcher.
+type filterMatcher struct {
+	FilterRule
+	searcher Searcher
+}
+
+// filterStack decides the lines to show by the rules of a filter stack.
+// The lines are passed in order.
+type filterStack struct {
+	includes []filterMatcher
+	excludes []filterMatcher
+	header   int
+	// minLevel hides the lines of a lower level, level returns the level of a line.
+	minLevel Level
+	level    func(lN int) Level
+	// before is the largest context of the include rules.
+	before       int
+	includeUntil int
+	excludeUntil int
+	// skipped are the last lines not shown, candidates for the context.
+	skipped []int
+}
+
+// newFilterStack returns the filter stack of the enabled rules.
+func newFilterStack(rules []FilterRule, header int, caseSensitive bool, regexpSearch bool) *filterStack {
+	f := &filterStack{header: header}
+	for _, r := range activeFilters(rules) {
+		m := filterMatcher{
+			FilterRule: r,
+			searcher:   NewWordSearcher(r.Pattern, caseSensitive, regexpSearch),
+		}
+		if r.Exclude {
+			f.excludes = append(f.excludes, m)
+			continue
+		}
+		f.includes = append(f.includes, m)
+		f.before = max(f.before, r.Context)
+	}
+	f.reset()
+	return f
+}
+
+// reset starts over from the first line.
+func (f *filterStack) reset() {
+	f.includeUntil = -1
+	f.excludeUntil = -1
+	f.skipped = f.skipped[:0]
+}
+
+// lines returns the line numbers to show for line lN, in order:
+// the lines before it in the context of an include rule and lN itself.
+// The header lines are always shown.
+func (f *filterStack) lines(lN int, line string) []int {
+	if lN < f.header {
+		return []int{lN}
+	}
+
+	if f.minLevel > LevelUnknown {
+		if lv := f.level(lN); lv != LevelUnknown && lv < f.minLevel {
+			return nil
+		}
+	}
+
+	for _, e := range f.excludes {
+		if e.searcher.Match(line) {
+			f.excludeUntil = max(f.excludeUntil, lN+e.Context)
+		}
+	}
+	if lN <= f.excludeUntil {
+		return nil
+	}
+
+	if len(f.includes) == 0 {
+		return []int{lN}
+	}
+
+	matched, before := false, 0
+	for _, in := range f.includes {
+		if in.searcher.Match(line) {
+			matched = true
+			before = max(before, in.Context)
+			f.includeUntil = max(f.includeUntil, lN+in.Context)
+		}
+	}
+
+	if !matched && lN > f.includeUntil {
+		f.skipped = append(f.skipped, lN)
+		if len(f.skipped) > f.before {
+			f.skipped = f.skipped[len(f.skipped)-f.before:]
+		}
+		return nil
+	}
+
+	var shown []int
+	for _, n := range f.skipped {
+		if n >= lN-before {
+			shown = append(shown, n)
+		}
+	}
+	f.skipped = f.skipped[:0]
+	return append(shown, lN)
+}
+
+// newFilterDocument returns a document with the lines of parent the stack shows.
+// The lines are filtered in the background and the document keeps
+// following the parent until stopFilter is called on the parent.
+func newFilterDocument(parent *Document, stack *filterStack) (*Document, error) {
+	m, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+
+	m.seekable = false
+	m.preventReload = true
+	m.parent = parent
+	m.FileName = parent.FileName
+	m.Caption = parent.Caption
+	m.Metadata = parent.Metadata
+	m.general = parent.general
+	m.LineNumMode = true
+	m.setSectionDelimiter(m.SectionDelimiter)
+	m.log = parent.log
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.filterCancel = cancel
+	go m.filterLines(ctx, stack)
+	return m, nil
+}
+
+// filterLines appends the lines of the parent the stack shows as they arrive.
+func (m *Document) filterLines(ctx context.Context, stack *filterStack) {
+	ticker := time.NewTicker(UpdateInterval)
+	defer ticker.Stop()
+
+	n := 0
+	for {
+		end := m.parent.BufEndNum()
+		// The parent was reloaded.
+		if end < n {
+			m.resetFilter()
+			stack.reset()
+			n = 0
+		}
+
+		for ; n < end; n++ {
+			if n%filterChunk == 0 && ctx.Err() != nil {
+				return
+			}
+			line := m.parent.GetLine(n)
+			for _, lN := range stack.lines(n, line) {
+				if lN != n {
+					m.appendFiltered(lN, m.parent.GetLine(lN))
+					continue
+				}
+				m.appendFiltered(lN, line)
+			}
+		}
+
+		eof := int32(0)
+		if m.parent.BufEOF() {
+			eof = 1
+		}
+		atomic.StoreInt32(&m.eof, eof)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// appendFiltered appends a line of the parent with its line number.
+func (m *Document) appendFiltered(lN int, line string) {
+	m.mu.Lock()
+	m.lines = append(m.lines, line)
+	m.lineNum = append(m.lineNum, lN)
+	m.endNum++
+	m.mu.Unlock()
+	atomic.StoreInt32(&m.changed, 1)
+}
+
+func (m *Document) resetFilter() {
+	m.mu.Lock()
+	m.lineNum = m.lineNum[:0]
+	m.mu.Unlock()
+	m.reset()
+}
+
+// stopFilter stops the filtered view of the document.
+func (m *Document) stopFilter() {
+	if m.filterDoc == nil {
+		return
+	}
+	m.filterDoc.filterCancel()
+	m.filterDoc = nil
+}
+
+// originLN returns the line number in the parent of a filtered document.
+func (m *Document) originLN(lN int) int {
+	if m.parent == nil {
+		return lN
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if lN < 0 || len(m.lineNum) == 0 {
+		return lN
+	}
+	if lN >= len(m.lineNum) {
+		return m.lineNum[len(m.lineNum)-1] + lN - len(m.lineNum) + 1
+	}
+	return m.lineNum[lN]
+}
+
+// filteredLN returns the first line of a filtered document
+// at or after the line number of the parent.
+func (m *Document) filteredLN(lN int) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return sort.SearchInts(m.lineNum, lN)
+}
+
+// lastLineNum returns the number of lines numbered in the gutter.
+func (m *Document) lastLineNum() int {
+	if m.parent != nil {
+		return m.parent.BufEndNum()
+	}
+	return m.BufEndNum()
+}
+
+// filtered returns true if the document has enabled filter rules
+// or a minimum level.
+func (m *Document) filtered() bool {
+	return len(activeFilters(m.Filters)) > 0 || m.MinLevel > LevelDebug
+}
+
+// filterStatus returns the enabled rules for the status line.
+func (m *Document) filterStatus() string {
+	active := activeFilters(m.Filters)
+	rules := make([]string, 0, len(active)+1)
+	if m.MinLevel > LevelDebug {
+		rules = append(rules, "level>="+m.MinLevel.String())
+	}
+	for _, r := range active {
+		rules = append(rules, r.String())
+	}
+	return strings.Join(rules, ", ")
+}
+
+// filterParent returns the document whose lines are filtered.
+func (root *Root) filterParent() *Document {
+	if root.Doc.parent != nil {
+		return root.Doc.parent
+	}
+	return root.Doc
+}
+
+// setFilterMode starts the input of a filter rule.
+func (root *Root) setFilterMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = Filter
+	input.EventInput = newFilterInput(input.SearchCandidate)
+}
+
+// filter pushes a rule on the filter stack, an empty word clears the stack
+// and the minimum level.
+func (root *Root) filter(word string) {
+	parent := root.filterParent()
+	if word == "" {
+		parent.Filters = nil
+		parent.MinLevel = LevelUnknown
+		root.applyFilters()
+		root.setMessage("Filter cleared")
+		return
+	}
+
+	rule := ParseFilterRule(word)
+	root.setSearcher(rule.Pattern, root.CaseSensitive)
+	// The stack may be shared with a mode, it is never changed in place.
+	parent.Filters = append(append([]FilterRule{}, parent.Filters...), rule)
+	root.applyFilters()
+	root.setMessagef("Filter %s", rule)
+}
+
+// setFilterRulesMode starts the input of the filter rules.
+func (root *Root) setFilterRulesMode() {
+	m := root.filterParent()
+	rules := m.Filters
+	if len(rules) == 0 && m.MinLevel <= LevelDebug {
+		root.setMessage("No filter rules")
+		return
+	}
+
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = FilterRules
+	input.EventInput = newFilterRulesInput(input.FilterRulesCandidate, rules)
+}
+
+// filterRules changes the filter stack with the input of the rules prompt:
+// "N" toggles rule N, "N=C" sets its context lines, "dN" deletes it
+// and "m NAME" saves the stack into the mode NAME.
+func (root *Root) filterRules(input string) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return
+	}
+
+	parent := root.filterParent()
+	if strings.HasPrefix(input, "m ") {
+		root.saveFilterMode(strings.TrimSpace(input[2:]), parent)
+		return
+	}
+
+	rules := append([]FilterRule{}, parent.Filters...)
+	del := strings.HasPrefix(input, "d")
+	if del {
+		input = input[1:]
+	}
+	num, ctx, setCtx := strings.Cut(input, "=")
+	i, err := strconv.Atoi(num)
+	if err != nil || i < 1 || i > len(rules) {
+		root.setMessagef("No filter rule %s", num)
+		return
+	}
+	i--
+
+	switch {
+	case del:
+		root.setMessagef("Delete filter %s", rules[i])
+		rules = append(rules[:i], rules[i+1:]...)
+	case setCtx:
+		c, err := strconv.Atoi(ctx)
+		if err != nil || c < 0 {
+			root.setMessage(ErrInvalidNumber.Error())
+			return
+		}
+		rules[i].Context = c
+		root.setMessagef("Filter %s", rules[i])
+	default:
+		rules[i].Disabled = !rules[i].Disabled
+		state := "on"
+		if rules[i].Disabled {
+			state = "off"
+		}
+		root.setMessagef("Filter %s %s", rules[i], state)
+	}
+
+	parent.Filters = rules
+	root.applyFilters()
+}
+
+// saveFilterMode saves the filter stack and the minimum level of the document
+// into a mode, the other settings of a new mode are those of the document.
+func (root *Root) saveFilterMode(name string, m *Document) {
+	if name == "" || name == "general" {
+		root.setMessagef("Invalid mode name %q", name)
+		return
+	}
+
+	mode, ok := root.Config.Mode[name]
+	if !ok {
+		mode = m.general
+	}
+	mode.Filters = m.Filters
+	mode.MinLevel = m.MinLevel
+	if root.Config.Mode == nil {
+		root.Config.Mode = make(map[string]general)
+	}
+	root.Config.Mode[name] = mode
+	root.input.ModeCandidate.list = toLast(root.input.ModeCandidate.list, name)
+	root.setMessagef("Save filters to mode %s", name)
+}
+
+// ApplyFilters shows the document filtered by its filter stack,
+// or the whole document if no rule is enabled.
+// Call it in the event loop after changing the settings of the document,
+// e.g. with Root.Call.
+func (root *Root) ApplyFilters() {
+	root.applyFilters()
+}
+
+func (root *Root) applyFilters() {
+	parent := root.filterParent()
+	if !parent.filtered() {
+		root.unfilter()
+		return
+	}
+
+	followMode := root.Doc.FollowMode
+	root.unfilter()
+
+	stack := newFilterStack(parent.Filters, parent.firstLine(), root.CaseSensitive, root.Config.RegexpSearch)
+	if parent.MinLevel > LevelDebug {
+		stack.minLevel = parent.MinLevel
+		stack.level = parent.lineLevel
+	}
+	m, err := newFilterDocument(parent, stack)
+	if err != nil {
+		root.setMessagef("filter: %s", err)
+		return
+	}
+	m.FollowMode = followMode
+
+	parent.filterDoc = m
+	root.setDocument(m)
+}
+
+// unfilter shows the parent of a filtered document again
+// at the line shown at the top of the filtered document.
+func (root *Root) unfilter() {
+	m := root.Doc
+	parent := m.parent
+	if parent == nil {
+		return
+	}
+
+	lN := m.originLN(m.topLN)
+	for _, mark := range m.marked {
+		orig := m.originLN(mark)
+		parent.marked = removeInt(parent.marked, orig)
+		parent.marked = append(parent.marked, orig)
+	}
+
+	parent.stopFilter()
+	parent.FollowMode = m.FollowMode
+	root.setDocument(parent)
+	root.moveLine(lN)
+}
+
+// filterRulesPrompt returns the rules of the stack numbered for the rules prompt,
+// disabled rules in parentheses.
+func filterRulesPrompt(rules []FilterRule) string {
+	var b strings.Builder
+	b.WriteString("Filters")
+	for i, r := range rules {
+		if r.Disabled {
+			fmt.Fprintf(&b, " %d:(%s)", i+1, r)
+			continue
+		}
+		fmt.Fprintf(&b, " %d:%s", i+1, r)
+	}
+	b.WriteString(" [N|N=C|dN|m mode]:")
+	return b.String()
+}
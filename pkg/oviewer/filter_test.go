@@ -0,0 +1,150 @@
+package oviewer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDocument_filter(t *testing.T) {
+	parent, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent.Header = 1
+	parent.Metadata = []string{"container: web"}
+	parent.append("header", "error 1", "ok", "error 2")
+
+	m, err := newFilterDocument(parent, newFilterStack([]FilterRule{{Pattern: "^error"}}, 1, false, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent.filterDoc = m
+	defer parent.stopFilter()
+
+	waitLines(t, m, 3)
+	parent.append("ok", "error 3")
+	waitLines(t, m, 4)
+
+	var lines []string
+	for i := 0; i < m.BufEndNum(); i++ {
+		lines = append(lines, m.GetLine(i))
+	}
+	if want := []string{"header", "error 1", "error 2", "error 3"}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("lines = %v, want %v", lines, want)
+	}
+	if got := m.originLN(2); got != 3 {
+		t.Errorf("originLN(2) = %d, want 3", got)
+	}
+	if got := m.filteredLN(4); got != 3 {
+		t.Errorf("filteredLN(4) = %d, want 3", got)
+	}
+	if got := m.lastLineNum(); got != 6 {
+		t.Errorf("lastLineNum() = %d, want 6", got)
+	}
+	if !reflect.DeepEqual(m.Metadata, parent.Metadata) {
+		t.Errorf("Metadata = %v, want the metadata of the parent", m.Metadata)
+	}
+}
+
+func Test_filterStack(t *testing.T) {
+	lines := []string{
+		"header",
+		"start",
+		"error one",
+		"  at main.go",
+		"DEBUG retry",
+		"  at retry.go",
+		"GET /health",
+		"ok",
+		"error two",
+		"done",
+	}
+	tests := []struct {
+		name     string
+		rules    []FilterRule
+		minLevel Level
+		want     []int
+	}{
+		{
+			name:  "include",
+			rules: []FilterRule{{Pattern: "error"}},
+			want:  []int{0, 2, 8},
+		},
+		{
+			name:  "include context",
+			rules: []FilterRule{{Pattern: "error", Context: 1}},
+			want:  []int{0, 1, 2, 3, 7, 8, 9},
+		},
+		{
+			name: "exclude",
+			rules: []FilterRule{
+				{Pattern: "health", Exclude: true},
+				{Pattern: "DEBUG", Exclude: true, Context: 1},
+			},
+			want: []int{0, 1, 2, 3, 7, 8, 9},
+		},
+		{
+			name: "include and exclude",
+			rules: []FilterRule{
+				{Pattern: "at "},
+				{Pattern: "retry", Exclude: true},
+			},
+			want: []int{0, 3},
+		},
+		{
+			name: "min level",
+			rules: []FilterRule{
+				{Pattern: "health", Exclude: true},
+			},
+			minLevel: LevelWarn,
+			// Only DEBUG has a level, the lines without one are shown.
+			want: []int{0, 1, 2, 3, 5, 7, 8, 9},
+		},
+		{
+			name: "disabled",
+			rules: []FilterRule{
+				{Pattern: "error"},
+				{Pattern: "health", Disabled: true},
+			},
+			want: []int{0, 2, 8},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFilterStack(tt.rules, 1, false, false)
+			f.minLevel = tt.minLevel
+			f.level = func(lN int) Level { return LineLevel(lines[lN]) }
+			var got []int
+			for lN, line := range lines {
+				got = append(got, f.lines(lN, line)...)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lines = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterRule_String(t *testing.T) {
+	for _, s := range []string{"error", "!health"} {
+		if got := ParseFilterRule(s).String(); got != s {
+			t.Errorf("ParseFilterRule(%q).String() = %q", s, got)
+		}
+	}
+	r := FilterRule{Pattern: "DEBUG", Exclude: true, Context: 2}
+	if got, want := r.String(), "!DEBUG -C2"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func waitLines(t *testing.T, m *Document, n int) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if m.BufEndNum() == n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("BufEndNum() = %d, want %d", m.BufEndNum(), n)
+}
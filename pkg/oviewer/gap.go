@@ -0,0 +1,146 @@
+package oviewer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// parseGapThreshold parses the gap threshold input in seconds
+// or as a duration (e.g. 90, 5m). Zero turns the gap mode off.
+func parseGapThreshold(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+		return n, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 || (d > 0 && d < time.Second) {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidTime, s)
+	}
+	return int(d.Round(time.Second) / time.Second), nil
+}
+
+// gapBefore returns the time between the line and the line with
+// a timestamp before it, if it is longer than the gap threshold.
+// Lines without a timestamp have no gap.
+func (m *Document) gapBefore(lN int) (time.Duration, bool) {
+	if m.GapThreshold <= 0 || lN <= m.firstLine() || lN >= m.BufEndNum() {
+		return 0, false
+	}
+	t, ok := LineTime(m.GetLine(lN))
+	if !ok {
+		return 0, false
+	}
+	prev, ok := m.lineTime(lN - 1)
+	if !ok {
+		return 0, false
+	}
+	d := t.Sub(prev)
+	return d, d > time.Duration(m.GapThreshold)*time.Second
+}
+
+// gapHighlight marks the last row of the line before a gap
+// with the style of the gap line, and with the length of the gap
+// at the right edge if the text of the row leaves room for it.
+func (root *Root) gapHighlight(lN int, y int) {
+	d, ok := root.Doc.gapBefore(lN)
+	if !ok {
+		return
+	}
+	root.lineStyle(y, root.StyleGapLine)
+
+	label := fmt.Sprintf(" %s gap ", formatDuration(d))
+	x := root.vWidth - len(label)
+	if x < root.rowEnd(y) {
+		return
+	}
+	style := applyStyle(tcell.StyleDefault.Reverse(true), root.StyleGapLine)
+	for i, r := range label {
+		root.Screen.SetContent(x+i, y, r, nil, style)
+	}
+}
+
+// rowEnd returns the x after the last non-blank cell of the row.
+func (root *Root) rowEnd(y int) int {
+	for x := root.vWidth - 1; x >= 0; x-- {
+		r, _, _, _ := root.GetContent(x, y)
+		if r != ' ' && r != 0 {
+			return x + 1
+		}
+	}
+	return 0
+}
+
+// setGapThresholdMode starts the input of the gap threshold.
+func (root *Root) setGapThresholdMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = GapThreshold
+	input.EventInput = newGapThresholdInput(input.GapCandidate)
+}
+
+// setGapThreshold sets the gap threshold of the document, and of its parent if filtered.
+func (root *Root) setGapThreshold(input string) {
+	threshold, err := parseGapThreshold(input)
+	if err != nil {
+		root.setMessage(err.Error())
+		return
+	}
+	for _, m := range []*Document{root.Doc, root.Doc.parent} {
+		if m != nil {
+			m.GapThreshold = threshold
+		}
+	}
+	if threshold == 0 {
+		root.setMessage("Gap mode off")
+		return
+	}
+	root.setMessagef("Mark gaps longer than %s", time.Duration(threshold)*time.Second)
+}
+
+// nextGap moves to the next gap, the line before it at the top.
+func (root *Root) nextGap() {
+	m := root.Doc
+	if m.GapThreshold <= 0 {
+		root.setMessage("no gap threshold")
+		return
+	}
+	root.resetSelect()
+	defer root.releaseEventBuffer()
+
+	for lN := m.topLN + m.firstLine() + 2; lN < m.BufEndNum(); lN++ {
+		if d, ok := m.gapBefore(lN); ok {
+			root.moveLine(lN - 1 - m.firstLine())
+			root.setMessagef("Gap %s", formatDuration(d))
+			return
+		}
+	}
+	root.setMessage("no next gap")
+}
+
+// prevGap moves to the previous gap, the line before it at the top.
+func (root *Root) prevGap() {
+	m := root.Doc
+	if m.GapThreshold <= 0 {
+		root.setMessage("no gap threshold")
+		return
+	}
+	root.resetSelect()
+	defer root.releaseEventBuffer()
+
+	for lN := m.topLN + m.firstLine(); lN > m.firstLine(); lN-- {
+		if d, ok := m.gapBefore(lN); ok {
+			root.moveLine(lN - 1 - m.firstLine())
+			root.setMessagef("Gap %s", formatDuration(d))
+			return
+		}
+	}
+	root.setMessage("no previous gap")
+}
@@ -0,0 +1,128 @@
+package oviewer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func Test_parseGapThreshold(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{input: "", want: 0},
+		{input: "0", want: 0},
+		{input: "90", want: 90},
+		{input: "5m", want: 300},
+		{input: "1m30s", want: 90},
+		{input: "500ms", wantErr: true},
+		{input: "-1m", wantErr: true},
+		{input: "long", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseGapThreshold(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGapThreshold() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseGapThreshold() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_gapBefore(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.append(
+		"2026-10-18T14:00:00Z start",
+		"2026-10-18T14:00:05Z request",
+		"    at main.go:10",
+		"2026-10-18T14:03:05Z timeout",
+		"2026-10-18T14:03:06Z retry",
+	)
+	m.GapThreshold = 60
+	tests := []struct {
+		name   string
+		lN     int
+		want   time.Duration
+		wantOk bool
+	}{
+		{name: "first", lN: 0, wantOk: false},
+		{name: "short", lN: 1, want: 5 * time.Second, wantOk: false},
+		{name: "no timestamp", lN: 2, wantOk: false},
+		{name: "gap after stack trace", lN: 3, want: 3 * time.Minute, wantOk: true},
+		{name: "after gap", lN: 4, want: time.Second, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := m.gapBefore(tt.lN)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("Document.gapBefore() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestRoot_gapHighlight(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.append(
+		"2026-10-18T14:00:00Z start",
+		"2026-10-18T14:03:05Z timeout",
+	)
+	m.GapThreshold = 60
+	root, err := NewOviewer(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.vWidth = 40
+
+	row := func(y int) string {
+		var b strings.Builder
+		for x := 0; x < root.vWidth; x++ {
+			r, _, _, _ := root.GetContent(x, y)
+			b.WriteRune(r)
+		}
+		return b.String()
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "short line",
+			text: "2026-10-18T14:00:00Z start",
+			want: "2026-10-18T14:00:00Z start" + strings.Repeat(" ", 4) + " 3m5s gap ",
+		},
+		{
+			name: "long line",
+			text: "2026-10-18T14:00:00Z " + strings.Repeat("x", 19),
+			want: "2026-10-18T14:00:00Z " + strings.Repeat("x", 19),
+		},
+	}
+	for y, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root.setContentString(0, y, StrToContents(tt.text, 8))
+			root.gapHighlight(1, y)
+			if got := strings.TrimRight(row(y), " \x00"); got != strings.TrimRight(tt.want, " ") {
+				t.Errorf("row = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -39,6 +39,8 @@ func KeyBindString(k KeyBind) string {
 	k.writeKeyBind(&b, actionSuspend, "suspend")
 	k.writeKeyBind(&b, actionHelp, "display help screen")
 	k.writeKeyBind(&b, actionLogDoc, "display log screen")
+	k.writeKeyBind(&b, actionLineDetail, "line detail of the current line (or right click)")
+	k.writeKeyBind(&b, actionCopyField, "copy the field value at the top of the line detail")
 	k.writeKeyBind(&b, actionSync, "screen sync")
 	k.writeKeyBind(&b, actionFollow, "follow mode toggle")
 	k.writeKeyBind(&b, actionFollowAll, "follow all mode toggle")
@@ -46,9 +48,21 @@ func KeyBindString(k KeyBind) string {
 
 	fmt.Fprint(&b, gchalk.Bold("\n\tDocker\n"))
 	fmt.Fprint(&b, "\n")
-	k.writeKeyBind(&b, "left", "previous container")
-	k.writeKeyBind(&b, "right", "next container")
-	k.writeKeyBind(&b, "ctrl+u", "retrieve all logs for current container")
+	k.writeKeyBind(&b, "prevContainer", "previous container or file")
+	k.writeKeyBind(&b, "nextContainer", "next container or file")
+	k.writeKeyBind(&b, "allLogs", "retrieve all logs for current container")
+	k.writeKeyBind(&b, "topPanel", "processes of current container (docker top)")
+	k.writeKeyBind(&b, "diffPanel", "changed files of current container (docker diff)")
+	k.writeKeyBind(&b, "exportSession", "export session snapshot")
+	k.writeKeyBind(&b, "systemReport", "write memory report to the log")
+
+	fmt.Fprint(&b, gchalk.Bold("\n\tReplay\n"))
+	fmt.Fprint(&b, "\n")
+	k.writeKeyBind(&b, "replayPause", "pause/resume replay")
+	k.writeKeyBind(&b, "replayForward", "seek replay forward")
+	k.writeKeyBind(&b, "replayBackward", "seek replay backward")
+	k.writeKeyBind(&b, "replayFaster", "faster replay")
+	k.writeKeyBind(&b, "replaySlower", "slower replay")
 
 	fmt.Fprint(&b, gchalk.Bold("\n\tMoving\n"))
 	fmt.Fprint(&b, "\n")
@@ -60,9 +74,11 @@ func KeyBindString(k KeyBind) string {
 	k.writeKeyBind(&b, actionMovePgUp, "backward by page")
 	k.writeKeyBind(&b, actionMoveHfDn, "forward a half page")
 	k.writeKeyBind(&b, actionMoveHfUp, "backward a half page")
+	k.writeKeyBind(&b, actionMoveLeft, "scroll left, previous column in column mode")
+	k.writeKeyBind(&b, actionMoveRight, "scroll right, next column in column mode")
 	k.writeKeyBind(&b, actionMoveHfLeft, "scroll left half screen")
 	k.writeKeyBind(&b, actionMoveHfRight, "scroll right half screen")
-	k.writeKeyBind(&b, actionGoLine, "go to line(input number)")
+	k.writeKeyBind(&b, actionGoLine, "go to line(input number) or time(14:05, RFC3339, -15m)")
 
 	/*
 		fmt.Fprint(&b, gchalk.Bold("\n\tMove document\n"))
@@ -86,6 +102,9 @@ func KeyBindString(k KeyBind) string {
 	k.writeKeyBind(&b, actionBackSearch, "backward search mode")
 	k.writeKeyBind(&b, actionNextSearch, "repeat forward search")
 	k.writeKeyBind(&b, actionNextBackSearch, "repeat backward search")
+	k.writeKeyBind(&b, actionFilter, "add a filter rule, !pattern hides lines (empty clears)")
+	k.writeKeyBind(&b, actionFilterRules, "toggle, delete or save the filter rules")
+	k.writeKeyBind(&b, actionMinLevel, "cycle the minimum log level (error, warn, info, debug)")
 
 	fmt.Fprint(&b, gchalk.Bold("\n\tChange display\n"))
 	fmt.Fprint(&b, "\n")
@@ -93,14 +112,19 @@ func KeyBindString(k KeyBind) string {
 	k.writeKeyBind(&b, actionColumnMode, "column mode toggle")
 	k.writeKeyBind(&b, actionAlternate, "alternate rows of style toggle")
 	k.writeKeyBind(&b, actionLineNumMode, "line number toggle")
+	k.writeKeyBind(&b, actionJSONMode, "JSON columns toggle")
+	k.writeKeyBind(&b, actionTimeMode, "cycle the timestamp display (zone, short, relative, delta)")
 
 	fmt.Fprint(&b, gchalk.Bold("\n\tChange Display with Input\n"))
 	fmt.Fprint(&b, "\n")
 	k.writeKeyBind(&b, actionViewMode, "view mode selection")
-	k.writeKeyBind(&b, actionDelimiter, "column delimiter string")
+	k.writeKeyBind(&b, actionDelimiter, "column delimiter string (logfmt for key=value columns)")
 	k.writeKeyBind(&b, actionHeader, "number of header lines")
 	k.writeKeyBind(&b, actionSkipLines, "number of skip lines")
 	k.writeKeyBind(&b, actionTabWidth, "TAB width")
+	k.writeKeyBind(&b, actionJSONFields, "JSON columns (e.g. time,level,msg,user)")
+	k.writeKeyBind(&b, actionHighlight, "toggle, delete or add highlight rules (pattern style)")
+	k.writeKeyBind(&b, actionGap, "mark time gaps longer than (e.g. 30s, 5m, 0 is off)")
 
 	fmt.Fprint(&b, gchalk.Bold("\n\tSection\n"))
 	fmt.Fprint(&b, "\n")
@@ -110,11 +134,14 @@ func KeyBindString(k KeyBind) string {
 	k.writeKeyBind(&b, actionPrevSection, "previous section")
 	k.writeKeyBind(&b, actionLastSection, "last section")
 	k.writeKeyBind(&b, actionFollowSection, "follow section mode toggle")
+	k.writeKeyBind(&b, actionNextGap, "next time gap")
+	k.writeKeyBind(&b, actionPrevGap, "previous time gap")
 
 	fmt.Fprint(&b, gchalk.Bold("\n\tClose and reload\n"))
 	fmt.Fprint(&b, "\n")
 	k.writeKeyBind(&b, actionCloseFile, "close file")
 	k.writeKeyBind(&b, actionReload, "reload file")
+	k.writeKeyBind(&b, actionSaveAs, "save to file (path [N-M|marked] [header])")
 	k.writeKeyBind(&b, actionWatch, "watch mode")
 	k.writeKeyBind(&b, actionWatchInterval, "set watch interval")
 
@@ -123,6 +150,7 @@ func KeyBindString(k KeyBind) string {
 	k.writeKeyBind(&b, inputCaseSensitive, "case-sensitive toggle")
 	k.writeKeyBind(&b, inputRegexpSearch, "regular expression search toggle")
 	k.writeKeyBind(&b, inputIncSearch, "incremental search toggle")
+	k.writeKeyBind(&b, inputHistorySearch, "reverse search in the input history")
 	return b.String()
 }
 
@@ -0,0 +1,264 @@
+package oviewer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// HighlightRule is a regular expression with the style
+// applied to its matches in the body.
+type HighlightRule struct {
+	// Pattern is a regular expression.
+	Pattern string
+	// Style is applied on top of the style of the line.
+	Style OVStyle
+	// Disabled keeps the rule without applying it.
+	Disabled bool
+}
+
+// String returns the rule as it is entered in the highlight input.
+func (r HighlightRule) String() string {
+	if s := styleString(r.Style); s != "" {
+		return r.Pattern + " " + s
+	}
+	return r.Pattern
+}
+
+// highlighter is an enabled highlight rule with the compiled pattern.
+type highlighter struct {
+	re    *regexp.Regexp
+	style OVStyle
+}
+
+// highlightRange is a range of contents of a line with the highlighter index.
+type highlightRange struct {
+	start int
+	end   int
+	n     int
+}
+
+// setHighlighters compiles the enabled highlight rules
+// and clears the highlights cached by the documents.
+func (root *Root) setHighlighters() error {
+	highlighters := make([]highlighter, 0, len(root.Config.Highlights))
+	var errs []string
+	for _, r := range root.Config.Highlights {
+		if r.Disabled || r.Pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		highlighters = append(highlighters, highlighter{re: re, style: r.Style})
+	}
+	root.highlighters = highlighters
+
+	for _, doc := range root.DocList {
+		doc.ClearCache()
+		if doc.filterDoc != nil {
+			doc.filterDoc.ClearCache()
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidHighlight, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// highlightRanges returns the ranges of the highlight rules in a line.
+// The ranges are cached with the contents of the line.
+func (root *Root) highlightRanges(lN int, lineStr string, posCV map[int]int) []highlightRange {
+	m := root.Doc
+	key := fmt.Sprintf("highlight:%d", lN)
+	if value, found := m.cache.Get(key); found {
+		if ranges, ok := value.([]highlightRange); ok {
+			return ranges
+		}
+	}
+
+	var ranges []highlightRange
+	for n, h := range root.highlighters {
+		for _, r := range h.re.FindAllStringIndex(lineStr, -1) {
+			if r[0] == r[1] {
+				continue
+			}
+			ranges = append(ranges, highlightRange{start: posCV[r[0]], end: posCV[r[1]], n: n})
+		}
+	}
+	m.cache.Set(key, ranges, 1)
+	return ranges
+}
+
+// userHighlight applies the styles of the highlight rules.
+// Apply style to contents.
+func (root *Root) userHighlight(lY int, lc contents, lineStr string, posCV map[int]int) {
+	if len(root.highlighters) == 0 {
+		return
+	}
+
+	for _, r := range root.highlightRanges(lY, lineStr, posCV) {
+		RangeStyle(lc, r.start, min(r.end, len(lc)), root.highlighters[r.n].style)
+	}
+}
+
+// setHighlightMode starts the input of the highlight rules.
+func (root *Root) setHighlightMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = Highlight
+	input.EventInput = newHighlightInput(input.HighlightCandidate, root.Config.Highlights)
+}
+
+// highlight changes the highlight rules with the input of the highlight prompt:
+// "N" toggles rule N, "dN" deletes it and "PATTERN [STYLE]" adds a rule.
+func (root *Root) highlight(input string) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return
+	}
+
+	// The rules may be shared with the config, they are never changed in place.
+	rules := append([]HighlightRule{}, root.Config.Highlights...)
+	num, del := input, strings.HasPrefix(input, "d")
+	if del {
+		num = input[1:]
+	}
+	if i, err := strconv.Atoi(num); err == nil {
+		if i < 1 || i > len(rules) {
+			root.setMessagef("No highlight rule %d", i)
+			return
+		}
+		i--
+		if del {
+			root.setMessagef("Delete highlight %s", rules[i])
+			rules = append(rules[:i], rules[i+1:]...)
+		} else {
+			rules[i].Disabled = !rules[i].Disabled
+			state := "on"
+			if rules[i].Disabled {
+				state = "off"
+			}
+			root.setMessagef("Highlight %s %s", rules[i], state)
+		}
+	} else {
+		rule := parseHighlightRule(input, root.StyleSearchHighlight)
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			root.setMessagef("%s: %s", ErrInvalidHighlight, err)
+			return
+		}
+		rules = append(rules, rule)
+		root.setMessagef("Highlight %s", rule)
+	}
+
+	root.Config.Highlights = rules
+	if err := root.setHighlighters(); err != nil {
+		root.setMessage(err.Error())
+	}
+}
+
+// parseHighlightRule returns the rule of a highlight input.
+// The last word is the style if it is a valid style, otherwise def is used.
+func parseHighlightRule(input string, def OVStyle) HighlightRule {
+	if i := strings.LastIndexByte(input, ' '); i > 0 {
+		if style, ok := parseStyle(input[i+1:]); ok {
+			return HighlightRule{Pattern: strings.TrimSpace(input[:i]), Style: style}
+		}
+	}
+	return HighlightRule{Pattern: input, Style: def}
+}
+
+// parseStyle parses a style such as "red", "bold,underline" or "white,bg:red".
+// A word that is not an attribute is the foreground color,
+// a word prefixed with "bg:" is the background color.
+func parseStyle(s string) (OVStyle, bool) {
+	var style OVStyle
+	for _, w := range strings.Split(s, ",") {
+		switch w {
+		case "blink":
+			style.Blink = true
+		case "bold":
+			style.Bold = true
+		case "dim":
+			style.Dim = true
+		case "italic":
+			style.Italic = true
+		case "reverse":
+			style.Reverse = true
+		case "underline":
+			style.Underline = true
+		case "strikethrough":
+			style.StrikeThrough = true
+		default:
+			color := strings.TrimPrefix(w, "bg:")
+			if !validColor(color) {
+				return OVStyle{}, false
+			}
+			if color != w {
+				style.Background = color
+			} else {
+				style.Foreground = color
+			}
+		}
+	}
+	return style, true
+}
+
+// validColor returns true if the color is a color name or a #rrggbb color.
+func validColor(color string) bool {
+	if _, ok := tcell.ColorNames[color]; ok {
+		return true
+	}
+	return strings.HasPrefix(color, "#") && tcell.GetColor(color) != tcell.ColorDefault
+}
+
+// styleString returns the style in the form parseStyle reads.
+func styleString(s OVStyle) string {
+	var words []string
+	if s.Foreground != "" {
+		words = append(words, s.Foreground)
+	}
+	if s.Background != "" {
+		words = append(words, "bg:"+s.Background)
+	}
+	for _, a := range []struct {
+		on   bool
+		name string
+	}{
+		{s.Blink, "blink"},
+		{s.Bold, "bold"},
+		{s.Dim, "dim"},
+		{s.Italic, "italic"},
+		{s.Reverse, "reverse"},
+		{s.Underline, "underline"},
+		{s.StrikeThrough, "strikethrough"},
+	} {
+		if a.on {
+			words = append(words, a.name)
+		}
+	}
+	return strings.Join(words, ",")
+}
+
+// highlightPrompt returns the numbered rules for the highlight prompt,
+// disabled rules in parentheses.
+func highlightPrompt(rules []HighlightRule) string {
+	var b strings.Builder
+	b.WriteString("Highlights")
+	for i, r := range rules {
+		if r.Disabled {
+			fmt.Fprintf(&b, " %d:(%s)", i+1, r.Pattern)
+			continue
+		}
+		fmt.Fprintf(&b, " %d:%s", i+1, r.Pattern)
+	}
+	b.WriteString(" [N|dN|pattern style]:")
+	return b.String()
+}
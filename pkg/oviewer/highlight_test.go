@@ -0,0 +1,77 @@
+package oviewer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func Test_parseHighlightRule(t *testing.T) {
+	def := OVStyle{Reverse: true}
+	tests := []struct {
+		input string
+		want  HighlightRule
+	}{
+		{input: "ERROR red", want: HighlightRule{Pattern: "ERROR", Style: OVStyle{Foreground: "red"}}},
+		{input: "req-[0-9a-f]+ underline", want: HighlightRule{Pattern: "req-[0-9a-f]+", Style: OVStyle{Underline: true}}},
+		{input: "WARN black,bg:yellow,bold", want: HighlightRule{Pattern: "WARN", Style: OVStyle{Foreground: "black", Background: "yellow", Bold: true}}},
+		{input: "connection refused", want: HighlightRule{Pattern: "connection refused", Style: def}},
+		{input: "timeout", want: HighlightRule{Pattern: "timeout", Style: def}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := parseHighlightRule(tt.input, def)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseHighlightRule() = %+v, want %+v", got, tt.want)
+			}
+			if tt.want.Style != def && got.String() != tt.input {
+				t.Errorf("String() = %q, want %q", got.String(), tt.input)
+			}
+		})
+	}
+}
+
+func TestRoot_highlight(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.append("ERROR from 10.0.0.1")
+	root, err := NewOviewer(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.Config.Highlights = []HighlightRule{
+		{Pattern: "ERROR", Style: OVStyle{Foreground: "red"}},
+	}
+	if err := root.setHighlighters(); err != nil {
+		t.Fatal(err)
+	}
+	root.highlight(`\d+\.\d+\.\d+\.\d+ darkcyan`)
+
+	lc := m.getContents(0, m.TabWidth)
+	lineStr, posCV := m.getContentsStr(0, lc)
+	root.userHighlight(0, lc, lineStr, posCV)
+	if fg, _, _ := lc[0].style.Decompose(); fg != tcell.ColorRed {
+		t.Errorf("foreground of ERROR = %v, want red", fg)
+	}
+	if fg, _, _ := lc[len(lc)-1].style.Decompose(); fg != tcell.ColorDarkCyan {
+		t.Errorf("foreground of the IP = %v, want darkcyan", fg)
+	}
+
+	root.highlight("1")
+	if !root.Config.Highlights[0].Disabled || len(root.highlighters) != 1 {
+		t.Errorf("highlight(1) did not disable the first rule: %+v", root.Config.Highlights)
+	}
+	lc = m.getContents(0, m.TabWidth)
+	root.userHighlight(0, lc, lineStr, posCV)
+	if fg, _, _ := lc[0].style.Decompose(); fg == tcell.ColorRed {
+		t.Error("ERROR is still red after disabling the rule")
+	}
+}
@@ -0,0 +1,236 @@
+package oviewer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// HistoryFile is the file the input histories are loaded from by NewInput
+// and saved to when Run returns. The histories are kept in memory only
+// if it is empty.
+var HistoryFile string
+
+// HistorySize is the maximum number of entries kept in each history.
+var HistorySize = 100
+
+// histories returns the candidate lists by their names in the history file.
+func (input *Input) histories() map[string]*candidate {
+	return map[string]*candidate{
+		"mode":             input.ModeCandidate,
+		"search":           input.SearchCandidate,
+		"goto":             input.GoCandidate,
+		"delimiter":        input.DelimiterCandidate,
+		"tabwidth":         input.TabWidthCandidate,
+		"watch":            input.WatchCandidate,
+		"writeba":          input.WriteBACandidate,
+		"sectiondelimiter": input.SectionDelmCandidate,
+		"sectionstart":     input.SectionStartCandidate,
+		"save":             input.SaveCandidate,
+		"filterrules":      input.FilterRulesCandidate,
+		"highlight":        input.HighlightCandidate,
+		"jsonfields":       input.JSONFieldsCandidate,
+		"gap":              input.GapCandidate,
+	}
+}
+
+// modeCandidate returns the candidate list of the current input mode.
+func (input *Input) modeCandidate() *candidate {
+	switch input.mode {
+	case ViewMode:
+		return input.ModeCandidate
+	case Search, Backsearch, Filter:
+		return input.SearchCandidate
+	case Goline:
+		return input.GoCandidate
+	case Delimiter:
+		return input.DelimiterCandidate
+	case TabWidth:
+		return input.TabWidthCandidate
+	case Watch:
+		return input.WatchCandidate
+	case WriteBA:
+		return input.WriteBACandidate
+	case SectionDelimiter:
+		return input.SectionDelmCandidate
+	case SectionStart:
+		return input.SectionStartCandidate
+	case SaveAs:
+		return input.SaveCandidate
+	case FilterRules:
+		return input.FilterRulesCandidate
+	case Highlight:
+		return input.HighlightCandidate
+	case JSONFields:
+		return input.JSONFieldsCandidate
+	case GapThreshold:
+		return input.GapCandidate
+	}
+	return nil
+}
+
+// loadHistory adds the saved entries after the default candidates.
+func (input *Input) loadHistory(fileName string) error {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var saved map[string][]string
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("%s: %w", fileName, err)
+	}
+
+	for name, c := range input.histories() {
+		for _, s := range saved[name] {
+			c.list = toLast(c.list, s)
+		}
+		c.list = capHistory(c.list)
+	}
+	return nil
+}
+
+// saveHistory writes the histories, deduplicated and capped to HistorySize.
+func (input *Input) saveHistory(fileName string) error {
+	saved := make(map[string][]string)
+	for name, c := range input.histories() {
+		var list []string
+		for _, s := range c.list {
+			list = toLast(list, s)
+		}
+		if len(list) > 0 {
+			saved[name] = capHistory(list)
+		}
+	}
+
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fileName), 0o700); err != nil {
+		return err
+	}
+
+	// A temporary file of its own keeps instances saving at once apart.
+	tmp, err := os.CreateTemp(filepath.Dir(fileName), filepath.Base(fileName)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), fileName)
+}
+
+func (root *Root) saveHistory() {
+	if HistoryFile == "" {
+		return
+	}
+	if err := root.input.saveHistory(HistoryFile); err != nil {
+		root.log(err)
+	}
+}
+
+func capHistory(list []string) []string {
+	if HistorySize > 0 && len(list) > HistorySize {
+		return list[len(list)-HistorySize:]
+	}
+	return list
+}
+
+// reverseSearch is the state of the reverse incremental history search.
+type reverseSearch struct {
+	// query is the string searched for in the history.
+	query string
+	// pos is the position of the current match in the history.
+	pos int
+	// failed is true if no entry contains the query.
+	failed bool
+}
+
+// inputHistorySearch starts the reverse incremental history search
+// of the current prompt, or finds the next older match.
+func (root *Root) inputHistorySearch() {
+	input := root.input
+	c := input.modeCandidate()
+	if c == nil {
+		return
+	}
+
+	if input.reverse == nil {
+		input.reverse = &reverseSearch{pos: len(c.list)}
+		return
+	}
+	input.reverseFind(c, input.reverse.pos-1)
+}
+
+// reverseFind searches the history backwards from the position
+// for an entry containing the query.
+func (input *Input) reverseFind(c *candidate, from int) {
+	r := input.reverse
+	if r.query == "" {
+		return
+	}
+
+	for i := min(from, len(c.list)-1); i >= 0; i-- {
+		if strings.Contains(c.list[i], r.query) {
+			r.pos = i
+			r.failed = false
+			input.value = c.list[i]
+			input.cursorX = runeWidth(input.value)
+			return
+		}
+	}
+	r.failed = true
+}
+
+// reverseKeyEvent handles the keystrokes during the reverse search.
+// It reports whether the key was used by the search.
+func (input *Input) reverseKeyEvent(ev *tcell.EventKey) bool {
+	r := input.reverse
+	c := input.modeCandidate()
+	if c == nil {
+		input.reverse = nil
+		return false
+	}
+
+	switch ev.Key() {
+	case tcell.KeyRune:
+		r.query += string(ev.Rune())
+		input.reverseFind(c, r.pos)
+		return true
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		runes := []rune(r.query)
+		if len(runes) > 0 {
+			r.query = string(runes[:len(runes)-1])
+		}
+		input.reverseFind(c, len(c.list)-1)
+		return true
+	}
+
+	// Other keys end the search and edit the match.
+	input.reverse = nil
+	return false
+}
+
+// reversePrompt returns the prompt of the reverse search.
+func (r *reverseSearch) prompt() string {
+	if r.failed {
+		return fmt.Sprintf("(failed reverse-i-search)`%s': ", r.query)
+	}
+	return fmt.Sprintf("(reverse-i-search)`%s': ", r.query)
+}
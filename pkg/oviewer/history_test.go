@@ -0,0 +1,70 @@
+package oviewer
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestInput_history(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "history.json")
+
+	size := HistorySize
+	HistorySize = 3
+	defer func() { HistorySize = size }()
+
+	input := NewInput()
+	input.SearchCandidate.list = []string{"a", "b", "a", "c", "d"}
+	if err := input.saveHistory(fileName); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(filepath.Dir(fileName), "*")); len(files) != 1 {
+		t.Errorf("files = %v, want the history file only", files)
+	}
+
+	loaded := NewInput()
+	if err := loaded.loadHistory(fileName); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "c", "d"}; !reflect.DeepEqual(loaded.SearchCandidate.list, want) {
+		t.Errorf("SearchCandidate = %v, want %v", loaded.SearchCandidate.list, want)
+	}
+	if n := len(loaded.DelimiterCandidate.list); n > HistorySize {
+		t.Errorf("DelimiterCandidate has %d entries, want at most %d", n, HistorySize)
+	}
+}
+
+func TestInput_reverseSearch(t *testing.T) {
+	input := NewInput()
+	input.mode = Search
+	input.SearchCandidate.list = []string{"error 500", "timeout", "error 404"}
+	input.reverse = &reverseSearch{pos: len(input.SearchCandidate.list)}
+
+	key := func(r rune) *tcell.EventKey {
+		return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)
+	}
+
+	for _, r := range "err" {
+		input.reverseKeyEvent(key(r))
+	}
+	if input.value != "error 404" {
+		t.Errorf("value = %q, want %q", input.value, "error 404")
+	}
+
+	// ctrl+r again finds the older match.
+	input.reverseFind(input.SearchCandidate, input.reverse.pos-1)
+	if input.value != "error 500" {
+		t.Errorf("value = %q, want %q", input.value, "error 500")
+	}
+
+	input.reverseKeyEvent(key('x'))
+	if !input.reverse.failed {
+		t.Error("reverse search expected to fail")
+	}
+
+	if input.reverseKeyEvent(tcell.NewEventKey(tcell.KeyLeft, 0, tcell.ModNone)) || input.reverse != nil {
+		t.Error("other keys should end the reverse search")
+	}
+}
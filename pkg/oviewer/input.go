@@ -3,6 +3,7 @@ package oviewer
 import (
 	"context"
 	"strconv"
+	"strings"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/mattn/go-runewidth"
@@ -26,6 +27,14 @@ type Input struct {
 	WriteBACandidate      *candidate
 	SectionDelmCandidate  *candidate
 	SectionStartCandidate *candidate
+	SaveCandidate         *candidate
+	FilterRulesCandidate  *candidate
+	HighlightCandidate    *candidate
+	JSONFieldsCandidate   *candidate
+	GapCandidate          *candidate
+
+	// reverse is the reverse history search, nil if not searching.
+	reverse *reverseSearch
 }
 
 // InputMode represents the state of the input.
@@ -58,6 +67,18 @@ const (
 	SectionDelimiter
 	// SectionStart is a section start position input mode.
 	SectionStart
+	// SaveAs is a save file name input mode.
+	SaveAs
+	// Filter is a filter pattern input mode.
+	Filter
+	// FilterRules is a filter rules input mode.
+	FilterRules
+	// Highlight is a highlight rules input mode.
+	Highlight
+	// JSONFields is a JSON fields input mode.
+	JSONFields
+	// GapThreshold is a gap threshold input mode.
+	GapThreshold
 )
 
 // InputEvent input key events.
@@ -71,6 +92,7 @@ func (root *Root) inputEvent(ctx context.Context, ev *tcell.EventKey) {
 
 	// confirmed.
 	input := root.input
+	input.reverse = nil
 	nev := input.EventInput.Confirm(input.value)
 	if err := root.Screen.PostEvent(nev); err != nil {
 		root.log(err)
@@ -101,8 +123,12 @@ func (root *Root) inputKeyEvent(ev *tcell.EventKey) bool {
 	if evKey == nil {
 		return false
 	}
+	if input.reverse != nil && input.reverseKeyEvent(evKey) {
+		return false
+	}
 	switch evKey.Key() {
 	case tcell.KeyEscape:
+		input.reverse = nil
 		input.value = ""
 		input.mode = Normal
 		return false
@@ -245,6 +271,7 @@ func NewInput() *Input {
 			"\t",
 			"|",
 			",",
+			LogfmtDelimiter,
 		},
 	}
 	i.TabWidthCandidate = &candidate{
@@ -286,7 +313,32 @@ func NewInput() *Input {
 			"0",
 		},
 	}
+	i.SaveCandidate = &candidate{
+		list: []string{},
+	}
+	i.FilterRulesCandidate = &candidate{
+		list: []string{},
+	}
+	i.HighlightCandidate = &candidate{
+		list: []string{},
+	}
+	i.JSONFieldsCandidate = &candidate{
+		list: []string{
+			strings.Join(DefaultJSONFields, ","),
+		},
+	}
+	i.GapCandidate = &candidate{
+		list: []string{
+			"0",
+			"10s",
+			"1m",
+		},
+	}
 	i.EventInput = &normalInput{}
+	if HistoryFile != "" {
+		// A broken history file starts empty histories.
+		_ = i.loadHistory(HistoryFile)
+	}
 	return &i
 }
 
@@ -388,6 +440,14 @@ func (root *Root) setSectionStartMode() {
 	input.EventInput = newSectionStartInput(input.SectionStartCandidate)
 }
 
+func (root *Root) setSaveAsMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = SaveAs
+	input.EventInput = newSaveInput(input.SaveCandidate)
+}
+
 // EventInput is a generic interface for inputs.
 type EventInput interface {
 	// Prompt returns the prompt string in the input field.
@@ -517,13 +577,13 @@ func newGotoInput(clist *candidate) *gotoInput {
 
 // Prompt returns the prompt string in the input field.
 func (g *gotoInput) Prompt() string {
-	return "Goto line:"
+	return "Goto line or time:"
 }
 
 // Confirm returns the event when the input is confirmed.
 func (g *gotoInput) Confirm(str string) tcell.Event {
 	g.value = str
-	if _, err := strconv.Atoi(str); err == nil {
+	if _, err := strconv.Atoi(str); err == nil || isGotoTime(str) {
 		g.clist.list = toLast(g.clist.list, str)
 		g.clist.p = 0
 	}
@@ -900,6 +960,226 @@ func (d *sectionStartInput) Down(str string) string {
 	return d.clist.down()
 }
 
+// saveInput represents the save as input mode.
+type saveInput struct {
+	value string
+	clist *candidate
+	tcell.EventTime
+}
+
+// newSaveInput returns saveInput.
+func newSaveInput(clist *candidate) *saveInput {
+	return &saveInput{clist: clist}
+}
+
+// Prompt returns the prompt string in the input field.
+func (s *saveInput) Prompt() string {
+	return "Save as:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (s *saveInput) Confirm(str string) tcell.Event {
+	s.value = str
+	s.clist.list = toLast(s.clist.list, str)
+	s.clist.p = 0
+	s.SetEventNow()
+	return s
+}
+
+// Up returns strings when the up key is pressed during input.
+func (s *saveInput) Up(str string) string {
+	return s.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (s *saveInput) Down(str string) string {
+	return s.clist.down()
+}
+
+// filterInput represents the filter input mode.
+type filterInput struct {
+	value string
+	clist *candidate
+	tcell.EventTime
+}
+
+// newFilterInput returns FilterInput.
+func newFilterInput(clist *candidate) *filterInput {
+	return &filterInput{clist: clist}
+}
+
+// Prompt returns the prompt string in the input field.
+func (f *filterInput) Prompt() string {
+	return "&"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (f *filterInput) Confirm(str string) tcell.Event {
+	f.value = str
+	f.clist.list = toLast(f.clist.list, str)
+	f.clist.p = 0
+	f.SetEventNow()
+	return f
+}
+
+// Up returns strings when the up key is pressed during input.
+func (f *filterInput) Up(str string) string {
+	return f.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (f *filterInput) Down(str string) string {
+	return f.clist.down()
+}
+
+// filterRulesInput represents the filter rules input mode.
+type filterRulesInput struct {
+	value  string
+	prompt string
+	clist  *candidate
+	tcell.EventTime
+}
+
+// newFilterRulesInput returns filterRulesInput.
+func newFilterRulesInput(clist *candidate, rules []FilterRule) *filterRulesInput {
+	return &filterRulesInput{clist: clist, prompt: filterRulesPrompt(rules)}
+}
+
+// Prompt returns the prompt string in the input field.
+func (f *filterRulesInput) Prompt() string {
+	return f.prompt
+}
+
+// Confirm returns the event when the input is confirmed.
+func (f *filterRulesInput) Confirm(str string) tcell.Event {
+	f.value = str
+	f.clist.list = toLast(f.clist.list, str)
+	f.clist.p = 0
+	f.SetEventNow()
+	return f
+}
+
+// Up returns strings when the up key is pressed during input.
+func (f *filterRulesInput) Up(str string) string {
+	return f.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (f *filterRulesInput) Down(str string) string {
+	return f.clist.down()
+}
+
+// highlightInput represents the highlight rules input mode.
+type highlightInput struct {
+	value  string
+	prompt string
+	clist  *candidate
+	tcell.EventTime
+}
+
+// newHighlightInput returns highlightInput.
+func newHighlightInput(clist *candidate, rules []HighlightRule) *highlightInput {
+	return &highlightInput{clist: clist, prompt: highlightPrompt(rules)}
+}
+
+// Prompt returns the prompt string in the input field.
+func (h *highlightInput) Prompt() string {
+	return h.prompt
+}
+
+// Confirm returns the event when the input is confirmed.
+func (h *highlightInput) Confirm(str string) tcell.Event {
+	h.value = str
+	h.clist.list = toLast(h.clist.list, str)
+	h.clist.p = 0
+	h.SetEventNow()
+	return h
+}
+
+// Up returns strings when the up key is pressed during input.
+func (h *highlightInput) Up(str string) string {
+	return h.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (h *highlightInput) Down(str string) string {
+	return h.clist.down()
+}
+
+// jsonFieldsInput represents the JSON fields input mode.
+type jsonFieldsInput struct {
+	value string
+	clist *candidate
+	tcell.EventTime
+}
+
+// newJSONFieldsInput returns jsonFieldsInput.
+func newJSONFieldsInput(clist *candidate) *jsonFieldsInput {
+	return &jsonFieldsInput{clist: clist}
+}
+
+// Prompt returns the prompt string in the input field.
+func (j *jsonFieldsInput) Prompt() string {
+	return "JSON fields:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (j *jsonFieldsInput) Confirm(str string) tcell.Event {
+	j.value = str
+	j.clist.list = toLast(j.clist.list, str)
+	j.clist.p = 0
+	j.SetEventNow()
+	return j
+}
+
+// Up returns strings when the up key is pressed during input.
+func (j *jsonFieldsInput) Up(str string) string {
+	return j.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (j *jsonFieldsInput) Down(str string) string {
+	return j.clist.down()
+}
+
+// gapThresholdInput represents the gap threshold input mode.
+type gapThresholdInput struct {
+	value string
+	clist *candidate
+	tcell.EventTime
+}
+
+// newGapThresholdInput returns gapThresholdInput.
+func newGapThresholdInput(clist *candidate) *gapThresholdInput {
+	return &gapThresholdInput{clist: clist}
+}
+
+// Prompt returns the prompt string in the input field.
+func (g *gapThresholdInput) Prompt() string {
+	return "Gap threshold:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (g *gapThresholdInput) Confirm(str string) tcell.Event {
+	g.value = str
+	if _, err := parseGapThreshold(str); err == nil {
+		g.clist.list = toLast(g.clist.list, str)
+		g.clist.p = 0
+	}
+	g.SetEventNow()
+	return g
+}
+
+// Up returns strings when the up key is pressed during input.
+func (g *gapThresholdInput) Up(str string) string {
+	return g.clist.up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (g *gapThresholdInput) Down(str string) string {
+	return g.clist.down()
+}
+
 func toLast(list []string, s string) []string {
 	if len(s) == 0 {
 		return list
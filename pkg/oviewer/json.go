@@ -0,0 +1,215 @@
+package oviewer
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/dimcz/viewer/pkg/timestamp"
+	"github.com/mattn/go-runewidth"
+)
+
+// DefaultJSONFields are the columns of the JSON mode if no fields are selected.
+var DefaultJSONFields = []string{"time", "level", "msg"}
+
+// jsonMaxWidth is the widest a JSON column is padded to.
+const jsonMaxWidth = 40
+
+// jsonAliases are the keys a column also shows if the line has no key of its name.
+var jsonAliases = map[string][]string{
+	"time":  {"ts", "timestamp", "@timestamp", "t"},
+	"level": {"lvl", "severity", "log.level"},
+	"msg":   {"message", "@message"},
+}
+
+// jsonField is a field of a JSON line in the order of the line.
+type jsonField struct {
+	key   string
+	value json.RawMessage
+}
+
+// parseJSONLine returns the fields of a line with one JSON object,
+// and the text before the object, such as the timestamp added by docker.
+func parseJSONLine(line string) (string, []jsonField, bool) {
+	start := strings.IndexByte(line, '{')
+	if start < 0 || strings.Contains(strings.TrimSpace(line[:start]), " ") {
+		return "", nil, false
+	}
+
+	dec := json.NewDecoder(strings.NewReader(line[start:]))
+	dec.UseNumber()
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return "", nil, false
+	}
+
+	var fields []jsonField
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return "", nil, false
+		}
+		key, ok := t.(string)
+		if !ok {
+			return "", nil, false
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return "", nil, false
+		}
+		fields = append(fields, jsonField{key: key, value: value})
+	}
+	if _, err := dec.Token(); err != nil {
+		return "", nil, false
+	}
+	if strings.TrimSpace(line[start+int(dec.InputOffset()):]) != "" {
+		return "", nil, false
+	}
+	return line[:start], fields, true
+}
+
+// jsonValue returns a value as text, strings without quotes.
+func jsonValue(field string, value json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		return s
+	}
+	if field == "time" {
+		if t, ok := timestamp.Epoch(string(value)); ok {
+			return t.UTC().Format("2006-01-02T15:04:05.000Z")
+		}
+	}
+	var b bytes.Buffer
+	if err := json.Compact(&b, value); err != nil {
+		return string(value)
+	}
+	return b.String()
+}
+
+// tailValue quotes a value of the k=v tail if needed.
+func tailValue(value json.RawMessage) string {
+	s := jsonValue("", value)
+	if s == "" || strings.ContainsAny(s, " \"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// renderJSON renders a JSON line as aligned columns of the selected fields
+// and a dim k=v tail of the other fields. Other lines are returned unchanged.
+// The columns widen as wider values are rendered.
+func (m *Document) renderJSON(line string) string {
+	prefix, fields, ok := parseJSONLine(line)
+	if !ok {
+		return line
+	}
+
+	selected := m.JSONFields
+	if len(selected) == 0 {
+		selected = DefaultJSONFields
+	}
+	if len(m.jsonWidths) != len(selected) {
+		m.jsonWidths = make([]int, len(selected))
+	}
+
+	used := make([]bool, len(fields))
+	var b strings.Builder
+	b.WriteString(prefix)
+	for i, name := range selected {
+		value := ""
+		if n := findJSONField(fields, name); n >= 0 {
+			value = jsonValue(name, fields[n].value)
+			used[n] = true
+		}
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(value)
+		if i == len(selected)-1 {
+			break
+		}
+
+		width := runewidth.StringWidth(value)
+		if width > m.jsonWidths[i] && width <= jsonMaxWidth {
+			m.jsonWidths[i] = width
+			// The lines rendered narrower are rendered again.
+			m.columnWidened = true
+			atomic.StoreInt32(&m.changed, 1)
+		}
+		if pad := m.jsonWidths[i] - width; pad > 0 {
+			b.WriteString(strings.Repeat(" ", pad))
+		}
+	}
+
+	var tail []string
+	for n, f := range fields {
+		if !used[n] {
+			tail = append(tail, f.key+"="+tailValue(f.value))
+		}
+	}
+	if len(tail) > 0 {
+		b.WriteString(" \x1b[2m")
+		b.WriteString(strings.Join(tail, " "))
+		b.WriteString("\x1b[0m")
+	}
+	return b.String()
+}
+
+// findJSONField returns the index of the field of the name or one of its aliases.
+func findJSONField(fields []jsonField, name string) int {
+	for n, f := range fields {
+		if f.key == name {
+			return n
+		}
+	}
+	for _, alias := range jsonAliases[name] {
+		for n, f := range fields {
+			if f.key == alias {
+				return n
+			}
+		}
+	}
+	return -1
+}
+
+// toggleJSONMode toggles the JSON mode.
+func (root *Root) toggleJSONMode() {
+	root.setJSONMode(!root.Doc.JSONMode, root.Doc.JSONFields)
+	root.setMessagef("Set JSONMode %t", root.Doc.JSONMode)
+}
+
+// setJSONFieldsMode starts the input of the JSON fields.
+func (root *Root) setJSONFieldsMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = JSONFields
+	input.EventInput = newJSONFieldsInput(input.JSONFieldsCandidate)
+}
+
+// setJSONFields sets the fields of the JSON mode and turns it on.
+// The fields are separated by commas or spaces, empty selects the default fields.
+func (root *Root) setJSONFields(input string) {
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+	root.setJSONMode(true, fields)
+	if len(fields) == 0 {
+		fields = DefaultJSONFields
+	}
+	root.setMessagef("JSON fields %s", strings.Join(fields, ","))
+}
+
+// setJSONMode sets the JSON mode of the document, and of its parent if filtered.
+func (root *Root) setJSONMode(on bool, fields []string) {
+	for _, m := range []*Document{root.Doc, root.Doc.parent} {
+		if m == nil {
+			continue
+		}
+		m.JSONMode = on
+		m.JSONFields = fields
+		m.jsonWidths = nil
+		m.ClearCache()
+	}
+}
@@ -0,0 +1,53 @@
+package oviewer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_renderJSON(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.JSONFields = []string{"time", "level", "msg"}
+
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{
+			name: "zap",
+			line: `{"level":"info","ts":1654077600.5,"msg":"started","port":8080}`,
+			want: "2022-06-01T10:00:00.500Z info started \x1b[2mport=8080\x1b[0m",
+		},
+		{
+			name: "aligned",
+			line: `{"time":"10:00","level":"warn","msg":"slow","query":"select 1"}`,
+			want: "10:00                    warn slow \x1b[2mquery=\"select 1\"\x1b[0m",
+		},
+		{
+			name: "docker timestamp",
+			line: `2022-06-01T10:00:00Z {"msg":"ok"}`,
+			want: "2022-06-01T10:00:00Z " + strings.Repeat(" ", 24) + " " + strings.Repeat(" ", 4) + " ok",
+		},
+		{
+			name: "plain",
+			line: `level=info msg="not json"`,
+			want: `level=info msg="not json"`,
+		},
+		{
+			name: "trailing text",
+			line: `{"msg":"ok"} and more`,
+			want: `{"msg":"ok"} and more`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.renderJSON(tt.line); got != tt.want {
+				t.Errorf("renderJSON() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
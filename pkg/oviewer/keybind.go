@@ -9,26 +9,26 @@ import (
 )
 
 const (
-	actionExit          = "exit"
-	actionWriteBA       = "set_write_exit"
-	actionCancel        = "cancel"
-	actionWriteExit     = "write_exit"
-	actionSuspend       = "suspend"
-	actionSync          = "sync"
-	actionFollow        = "follow_mode"
-	actionFollowAll     = "follow_all"
-	actionFollowSection = "follow_section"
-	actionCloseFile     = "close_file"
-	actionReload        = "reload"
-	actionWatch         = "watch"
-	actionWatchInterval = "watch_interval"
-	actionHelp          = "help"
-	actionLogDoc        = "logdoc"
-	actionMoveDown      = "down"
-	actionMoveUp        = "up"
-	actionMoveTop       = "top"
-	// actionMoveLeft       = "left"
-	// actionMoveRight      = "right"
+	actionExit           = "exit"
+	actionWriteBA        = "set_write_exit"
+	actionCancel         = "cancel"
+	actionWriteExit      = "write_exit"
+	actionSuspend        = "suspend"
+	actionSync           = "sync"
+	actionFollow         = "follow_mode"
+	actionFollowAll      = "follow_all"
+	actionFollowSection  = "follow_section"
+	actionCloseFile      = "close_file"
+	actionReload         = "reload"
+	actionWatch          = "watch"
+	actionWatchInterval  = "watch_interval"
+	actionHelp           = "help"
+	actionLogDoc         = "logdoc"
+	actionMoveDown       = "down"
+	actionMoveUp         = "up"
+	actionMoveTop        = "top"
+	actionMoveLeft       = "left"
+	actionMoveRight      = "right"
 	actionMoveHfLeft     = "half_left"
 	actionMoveHfRight    = "half_right"
 	actionMoveBottom     = "bottom"
@@ -64,39 +64,53 @@ const (
 	// actionPreviousDoc    = "previous_doc"
 	//	actionCloseDoc       = "close_doc"
 	actionToggleMouse = "toggle_mouse"
+	actionSaveAs      = "save_as"
+	actionFilter      = "filter"
+	actionFilterRules = "filter_rules"
+	actionHighlight   = "highlight"
+	actionMinLevel    = "min_level"
+	actionJSONMode    = "json_mode"
+	actionJSONFields  = "json_fields"
+	actionLineDetail  = "line_detail"
+	actionCopyField   = "copy_field"
+	actionTimeMode    = "time_mode"
+	actionGap         = "gap_threshold"
+	actionNextGap     = "next_gap"
+	actionPrevGap     = "prev_gap"
 
 	inputCaseSensitive = "input_casesensitive"
 	inputIncSearch     = "input_incsearch"
 	inputRegexpSearch  = "input_regexp_search"
+	inputHistorySearch = "input_history_search"
 )
 
 func (root *Root) setHandler() map[string]func() {
 	return map[string]func(){
-		actionExit:          root.Quit,
-		actionWriteBA:       root.setWriteBAMode,
-		actionCancel:        root.Cancel,
-		actionWriteExit:     root.WriteQuit,
-		actionSuspend:       root.suspend,
-		actionSync:          root.ViewSync,
-		actionFollow:        root.toggleFollowMode,
-		actionFollowAll:     root.toggleFollowAll,
-		actionFollowSection: root.toggleFollowSection,
-		actionReload:        root.Reload,
-		actionWatch:         root.toggleWatch,
-		actionWatchInterval: root.setWatchIntervalMode,
-		actionCloseFile:     root.closeFile,
-		actionHelp:          root.helpDisplay,
-		actionLogDoc:        root.logDisplay,
-		actionMoveDown:      root.moveDown,
-		actionMoveUp:        root.moveUp,
-		actionMoveTop:       root.moveTop,
-		actionMoveBottom:    root.moveBottom,
-		actionMovePgUp:      root.movePgUp,
-		actionMovePgDn:      root.movePgDn,
-		actionMoveHfUp:      root.moveHfUp,
-		actionMoveHfDn:      root.moveHfDn,
-		// actionMoveLeft:      root.moveLeft,
-		// actionMoveRight:      root.moveRight,
+		actionExit:           root.Quit,
+		actionWriteBA:        root.setWriteBAMode,
+		actionCancel:         root.Cancel,
+		actionWriteExit:      root.WriteQuit,
+		actionSuspend:        root.suspend,
+		actionSync:           root.ViewSync,
+		actionFollow:         root.toggleFollowMode,
+		actionFollowAll:      root.toggleFollowAll,
+		actionFollowSection:  root.toggleFollowSection,
+		actionReload:         root.Reload,
+		actionWatch:          root.toggleWatch,
+		actionWatchInterval:  root.setWatchIntervalMode,
+		actionCloseFile:      root.closeFile,
+		actionHelp:           root.helpDisplay,
+		actionLogDoc:         root.logDisplay,
+		actionMoveDown:       root.moveDown,
+		actionMoveUp:         root.moveUp,
+		actionMoveTop:        root.moveTop,
+		actionMoveBottom:     root.moveBottom,
+		actionMovePgUp:       root.movePgUp,
+		actionMovePgDn:       root.movePgDn,
+		actionMoveHfUp:       root.moveHfUp,
+		actionMoveHfDn:       root.moveHfDn,
+		actionMoveLeft:       root.moveLeft,
+		actionMoveRight:      root.moveRight,
 		actionMoveHfLeft:     root.moveHfLeft,
 		actionMoveHfRight:    root.moveHfRight,
 		actionSection:        root.setSectionDelimiterMode,
@@ -127,9 +141,23 @@ func (root *Root) setHandler() map[string]func() {
 		// actionPreviousDoc:    root.previousDoc,
 		// actionCloseDoc:       root.closeDocument,
 		actionToggleMouse:  root.toggleMouse,
+		actionSaveAs:       root.setSaveAsMode,
+		actionFilter:       root.setFilterMode,
+		actionFilterRules:  root.setFilterRulesMode,
+		actionHighlight:    root.setHighlightMode,
+		actionMinLevel:     root.cycleMinLevel,
+		actionJSONMode:     root.toggleJSONMode,
+		actionJSONFields:   root.setJSONFieldsMode,
+		actionLineDetail:   root.lineDetailDisplay,
+		actionCopyField:    root.copyField,
+		actionTimeMode:     root.cycleTimeMode,
+		actionGap:          root.setGapThresholdMode,
+		actionNextGap:      root.nextGap,
+		actionPrevGap:      root.prevGap,
 		inputCaseSensitive: root.inputCaseSensitive,
 		inputIncSearch:     root.inputIncSearch,
 		inputRegexpSearch:  root.inputRegexpSearch,
+		inputHistorySearch: root.inputHistorySearch,
 	}
 }
 
@@ -139,30 +167,30 @@ type KeyBind map[string][]string
 // GetKeyBinds returns the current key mapping.
 func GetKeyBinds(bind map[string][]string) map[string][]string {
 	keyBind := map[string][]string{
-		actionExit:          {"Escape", "q"},
-		actionWriteBA:       {"ctrl+q"},
-		actionCancel:        {"ctrl+c"},
-		actionWriteExit:     {"Q"},
-		actionSync:          {"ctrl+l"},
-		actionFollow:        {"ctrl+f"},
-		actionFollowAll:     {"ctrl+a"},
-		actionFollowSection: {"F2"},
-		actionCloseFile:     {"ctrl+F9", "ctrl+alt+s"},
-		actionReload:        {"F5", "ctrl+alt+l"},
-		actionWatch:         {"F4", "ctrl+alt+w"},
-		actionWatchInterval: {"ctrl+w"},
-		actionHelp:          {"h", "ctrl+F1", "ctrl+alt+c"},
-		actionLogDoc:        {"ctrl+F2", "ctrl+alt+e"},
-		actionMoveDown:      {"Enter", "Down", "ctrl+N"},
-		actionMoveUp:        {"Up", "ctrl+p"},
-		actionMoveTop:       {"Home"},
-		actionMoveBottom:    {"End"},
-		actionMovePgUp:      {"PageUp", "ctrl+b"},
-		actionMovePgDn:      {"PageDown", "ctrl+v"},
-		actionMoveHfUp:      {"ctrl+u"},
-		actionMoveHfDn:      {"ctrl+d"},
-		// actionMoveLeft:       {"left"},
-		// actionMoveRight:      {"right"},
+		actionExit:           {"Escape", "q"},
+		actionWriteBA:        {"ctrl+q"},
+		actionCancel:         {"ctrl+c"},
+		actionWriteExit:      {"Q"},
+		actionSync:           {"ctrl+l"},
+		actionFollow:         {"ctrl+f"},
+		actionFollowAll:      {"ctrl+a"},
+		actionFollowSection:  {"F2"},
+		actionCloseFile:      {"ctrl+F9", "ctrl+alt+s"},
+		actionReload:         {"F5", "ctrl+alt+l"},
+		actionWatch:          {"F4", "ctrl+alt+w"},
+		actionWatchInterval:  {"ctrl+w"},
+		actionHelp:           {"h", "ctrl+F1", "ctrl+alt+c"},
+		actionLogDoc:         {"ctrl+F2", "ctrl+alt+e"},
+		actionMoveDown:       {"Enter", "Down", "ctrl+N"},
+		actionMoveUp:         {"Up", "ctrl+p"},
+		actionMoveTop:        {"Home"},
+		actionMoveBottom:     {"End"},
+		actionMovePgUp:       {"PageUp", "ctrl+b"},
+		actionMovePgDn:       {"PageDown", "ctrl+v"},
+		actionMoveHfUp:       {"ctrl+u"},
+		actionMoveHfDn:       {"ctrl+d"},
+		actionMoveLeft:       {"alt+left"},
+		actionMoveRight:      {"alt+right"},
 		actionMoveHfLeft:     {"ctrl+left"},
 		actionMoveHfRight:    {"ctrl+right"},
 		actionSection:        {"alt+d"},
@@ -194,10 +222,24 @@ func GetKeyBinds(bind map[string][]string) map[string][]string {
 		// actionCloseDoc:       {"ctrl+k"},
 		actionToggleMouse: {"ctrl+alt+r"},
 		actionSuspend:     {"ctrl+z"},
+		actionSaveAs:      {"S"},
+		actionFilter:      {"&"},
+		actionFilterRules: {"|"},
+		actionHighlight:   {"*"},
+		actionMinLevel:    {"L"},
+		actionJSONMode:    {"J"},
+		actionJSONFields:  {"alt+j"},
+		actionLineDetail:  {"v"},
+		actionCopyField:   {"y"},
+		actionTimeMode:    {"Z"},
+		actionGap:         {"alt+g"},
+		actionNextGap:     {")"},
+		actionPrevGap:     {"("},
 
 		inputCaseSensitive: {"alt+c"},
 		inputIncSearch:     {"alt+i"},
 		inputRegexpSearch:  {"alt+r"},
+		inputHistorySearch: {"ctrl+r"},
 	}
 
 	for k, v := range bind {
@@ -0,0 +1,270 @@
+package oviewer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Level is the log level of a line.
+type Level int8
+
+const (
+	// LevelUnknown is a line without a level.
+	LevelUnknown Level = iota
+	// LevelDebug includes trace.
+	LevelDebug
+	// LevelInfo includes notice.
+	LevelInfo
+	// LevelWarn is a warning.
+	LevelWarn
+	// LevelError includes critical, fatal and panic.
+	LevelError
+)
+
+// levelNum is the number of levels.
+const levelNum = int(LevelError) + 1
+
+var levelNames = [levelNum]string{"", "debug", "info", "warn", "error"}
+
+// String returns the name of the level.
+func (l Level) String() string {
+	if l < 0 || int(l) >= levelNum {
+		return ""
+	}
+	return levelNames[l]
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (l *Level) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*l = LevelUnknown
+		return nil
+	}
+	lv := parseLevel(string(text))
+	if lv == LevelUnknown {
+		return fmt.Errorf("%w: %s", ErrInvalidLevel, text)
+	}
+	*l = lv
+	return nil
+}
+
+// parseLevel returns the level of a level name or a numeric level as in pino and bunyan.
+func parseLevel(s string) Level {
+	switch strings.ToLower(s) {
+	case "trace", "debug", "dbg", "verbose", "d", "t":
+		return LevelDebug
+	case "info", "information", "notice", "i":
+		return LevelInfo
+	case "warn", "warning", "w":
+		return LevelWarn
+	case "error", "err", "crit", "critical", "alert", "emerg", "fatal", "panic", "dpanic", "severe", "e", "f":
+		return LevelError
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return LevelUnknown
+	}
+	switch {
+	case n < 30:
+		return LevelDebug
+	case n < 40:
+		return LevelInfo
+	case n < 50:
+		return LevelWarn
+	default:
+		return LevelError
+	}
+}
+
+var (
+	// levelJSONReg matches the level field of JSON logs (zap, logrus, pino).
+	levelJSONReg = regexp.MustCompile(`"(?i:level|lvl|severity|loglevel|log\.level)"\s*:\s*(?:"([^"]+)"|(\d+))`)
+	// levelKeyReg matches the level of logfmt logs (logrus text, go-kit).
+	levelKeyReg = regexp.MustCompile(`(?i)\b(?:level|lvl|severity)=["']?(\w+)`)
+	// levelKlogReg matches the header of klog and glog.
+	levelKlogReg = regexp.MustCompile(`^([IWEF])\d{4} \d\d:\d\d:\d\d`)
+	// levelBracketReg matches the level of the nginx error log.
+	levelBracketReg = regexp.MustCompile(`\[(?i:(trace|debug|info|notice|warn|warning|error|crit|alert|emerg|fatal))\]`)
+	// levelWordReg matches a level keyword (zap console, Python logging,
+	// the syslog severities of journal and syslog entries).
+	levelWordReg = regexp.MustCompile(`\b(TRACE|DEBUG|INFO|NOTICE|WARN|WARNING|ERR|ERROR|CRIT|CRITICAL|ALERT|EMERG|FATAL|PANIC)\b`)
+	// levelAccessReg matches the status of the nginx access log.
+	levelAccessReg = regexp.MustCompile(`HTTP/[\d.]+" ([1-5])\d\d `)
+)
+
+// LineLevel returns the level of a log line.
+// A level field is preferred to a keyword in the message.
+func LineLevel(line string) Level {
+	line = stripEscapeSequence(line)
+	if m := levelJSONReg.FindStringSubmatch(line); m != nil {
+		return parseLevel(m[1] + m[2])
+	}
+	if m := levelKeyReg.FindStringSubmatch(line); m != nil {
+		return parseLevel(m[1])
+	}
+	if m := levelKlogReg.FindStringSubmatch(line); m != nil {
+		return parseLevel(m[1])
+	}
+	if m := levelBracketReg.FindStringSubmatch(line); m != nil {
+		return parseLevel(m[1])
+	}
+	if m := levelWordReg.FindStringSubmatch(line); m != nil {
+		return parseLevel(m[1])
+	}
+	if m := levelAccessReg.FindStringSubmatch(line); m != nil {
+		switch m[1] {
+		case "5":
+			return LevelError
+		case "4":
+			return LevelWarn
+		default:
+			return LevelInfo
+		}
+	}
+	return LevelUnknown
+}
+
+const (
+	// levelScanChunk is the number of lines classified per draw.
+	levelScanChunk = 10000
+	// levelLookBack is the number of lines a line without
+	// a level looks back for the level it takes.
+	levelLookBack = 100
+)
+
+// scanLevels classifies the lines up to end. Only lines with a level are counted.
+// A line without a level takes the level of the line with a level
+// before it, such as the lines of a stack trace.
+func (m *Document) scanLevels(end int) {
+	end = min(end, m.BufEndNum())
+	for lN := len(m.levels); lN < end; lN++ {
+		lv := LineLevel(m.GetLine(lN))
+		switch {
+		case lv != LevelUnknown:
+			m.levelCount[lv]++
+			m.levelLast = lN
+		case len(m.levels) > 0 && lN-m.levelLast <= levelLookBack:
+			lv = m.levels[m.levelLast]
+		}
+		m.levels = append(m.levels, lv)
+	}
+}
+
+// lineLevel returns the level of the line.
+// The lines up to it are classified first, in order,
+// so it is called by the level filter outside the event loop.
+func (m *Document) lineLevel(lN int) Level {
+	if m.parent != nil {
+		return m.parent.lineLevel(m.originLN(lN))
+	}
+	if lN < 0 || lN >= m.BufEndNum() {
+		return LevelUnknown
+	}
+
+	m.levelMu.Lock()
+	defer m.levelMu.Unlock()
+	m.scanLevels(lN + 1)
+	return m.levels[lN]
+}
+
+// drawLevel returns the level of the line being drawn. Lines not classified
+// yet are classified alone, without the level of a line before them,
+// so drawing does not classify all the lines up to them.
+func (m *Document) drawLevel(lN int) Level {
+	if m.parent != nil {
+		return m.parent.drawLevel(m.originLN(lN))
+	}
+	if lN < 0 || lN >= m.BufEndNum() {
+		return LevelUnknown
+	}
+
+	m.levelMu.Lock()
+	scanned := lN < len(m.levels)
+	lv := LevelUnknown
+	if scanned {
+		lv = m.levels[lN]
+	}
+	m.levelMu.Unlock()
+	if !scanned {
+		lv = LineLevel(m.GetLine(lN))
+	}
+	return lv
+}
+
+// levelCounts returns the number of lines of each level classified so far.
+// The document stays changed until all lines are classified.
+func (m *Document) levelCounts() [levelNum]int {
+	if m.parent != nil {
+		return m.parent.levelCounts()
+	}
+
+	m.levelMu.Lock()
+	defer m.levelMu.Unlock()
+	m.scanLevels(len(m.levels) + levelScanChunk)
+	if len(m.levels) < m.BufEndNum() {
+		atomic.StoreInt32(&m.changed, 1)
+	}
+	return m.levelCount
+}
+
+// resetLevels clears the levels of the lines.
+func (m *Document) resetLevels() {
+	m.levelMu.Lock()
+	defer m.levelMu.Unlock()
+	m.levels = m.levels[:0]
+	m.levelLast = 0
+	m.levelCount = [levelNum]int{}
+}
+
+// levelStatus returns the counts of the levels for the status line.
+func (m *Document) levelStatus() string {
+	counts := m.levelCounts()
+	var s []string
+	for lv := LevelError; lv > LevelUnknown; lv-- {
+		if counts[lv] > 0 {
+			s = append(s, fmt.Sprintf("%c:%d", strings.ToUpper(lv.String())[0], counts[lv]))
+		}
+	}
+	return strings.Join(s, " ")
+}
+
+// levelStyle applies the style of the level of the line.
+// Apply style to contents.
+func (root *Root) levelStyle(lY int, lc contents) {
+	var s OVStyle
+	switch root.Doc.drawLevel(lY) {
+	case LevelError:
+		s = root.StyleLevelError
+	case LevelWarn:
+		s = root.StyleLevelWarn
+	case LevelInfo:
+		s = root.StyleLevelInfo
+	case LevelDebug:
+		s = root.StyleLevelDebug
+	default:
+		return
+	}
+	RangeStyle(lc, 0, len(lc), s)
+}
+
+// cycleMinLevel cycles the minimum level of the lines shown
+// through error, warn, info and debug, which shows all lines.
+func (root *Root) cycleMinLevel() {
+	m := root.filterParent()
+	switch m.MinLevel {
+	case LevelUnknown, LevelDebug:
+		m.MinLevel = LevelError
+	default:
+		m.MinLevel--
+	}
+	root.applyFilters()
+	root.setMessagef("Minimum level %s", m.MinLevel)
+}
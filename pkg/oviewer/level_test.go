@@ -0,0 +1,105 @@
+package oviewer
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestLineLevel(t *testing.T) {
+	tests := []struct {
+		line string
+		want Level
+	}{
+		{line: `time="2022-06-01T10:00:00Z" level=warning msg="slow query"`, want: LevelWarn},
+		{line: `{"level":"error","ts":1654077600.1,"msg":"failed","caller":"main.go:10"}`, want: LevelError},
+		{line: `{"level":30,"time":1654077600000,"msg":"listening"}`, want: LevelInfo},
+		{line: `2022-06-01T10:00:00.000Z	DEBUG	cache	miss`, want: LevelDebug},
+		{line: `INFO:root:started worker`, want: LevelInfo},
+		{line: `2022-06-01 10:00:00,123 - app - CRITICAL - out of memory`, want: LevelError},
+		{line: `2022/06/01 10:00:00 [error] 7#7: *1 connect() failed`, want: LevelError},
+		{line: `10.0.0.1 - - [01/Jun/2022:10:00:00 +0000] "GET /missing HTTP/1.1" 404 153 "-" "curl"`, want: LevelWarn},
+		{line: `E0601 10:00:00.000000       1 reflector.go:138] failed to list`, want: LevelError},
+		{line: "\x1b[31mERRO\x1b[0m[0000] level=error msg=boom", want: LevelError},
+		{line: `    at com.example.Main.run(Main.java:10)`, want: LevelUnknown},
+		{line: `2022-06-01T10:00:00Z CRIT disk failure`, want: LevelError},
+		{line: `2022-06-01T10:00:00Z EMERG kernel panic`, want: LevelError},
+		{line: `2022-06-01T10:00:00Z ALERT raid degraded`, want: LevelError},
+		{line: `<3>ERR sshd: bad key`, want: LevelError},
+	}
+	for _, tt := range tests {
+		if got := LineLevel(tt.line); got != tt.want {
+			t.Errorf("LineLevel(%q) = %s, want %s", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestDocument_lineLevel(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.append("INFO start", "ERROR failed", "    at main.go:10", "WARN slow", "plain")
+
+	want := []Level{LevelInfo, LevelError, LevelError, LevelWarn, LevelWarn}
+	if got := m.lineLevel(2); got != LevelError {
+		t.Errorf("lineLevel(2) before scan = %s, want error", got)
+	}
+	if len(m.levels) != 3 {
+		t.Errorf("lineLevel(2) classified %d lines, want 3", len(m.levels))
+	}
+	for lN, lv := range want {
+		if got := m.lineLevel(lN); got != lv {
+			t.Errorf("lineLevel(%d) before scan = %s, want %s", lN, got, lv)
+		}
+	}
+
+	counts := m.levelCounts()
+	if counts[LevelError] != 1 || counts[LevelWarn] != 1 || counts[LevelInfo] != 1 {
+		t.Errorf("levelCounts() = %v", counts)
+	}
+	for lN, lv := range want {
+		if got := m.lineLevel(lN); got != lv {
+			t.Errorf("lineLevel(%d) = %s, want %s", lN, got, lv)
+		}
+	}
+	if got, want := m.levelStatus(), "E:1 W:1 I:1"; got != want {
+		t.Errorf("levelStatus() = %q, want %q", got, want)
+	}
+}
+
+func TestDocument_drawLevel(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.append("INFO start", "ERROR failed", "    at main.go:10")
+
+	// Lines not classified yet are classified alone.
+	want := []Level{LevelInfo, LevelError, LevelUnknown}
+	for lN, lv := range want {
+		if got := m.drawLevel(lN); got != lv {
+			t.Errorf("drawLevel(%d) before scan = %s, want %s", lN, got, lv)
+		}
+	}
+	if len(m.levels) != 0 {
+		t.Errorf("drawLevel() classified %d lines, want 0", len(m.levels))
+	}
+
+	m.levelCounts()
+	if got := m.drawLevel(2); got != LevelError {
+		t.Errorf("drawLevel(2) = %s, want error", got)
+	}
+}
+
+func TestLevel_UnmarshalText(t *testing.T) {
+	var g General
+	if err := json.Unmarshal([]byte(`{"MinLevel":"warning"}`), &g); err != nil {
+		t.Fatal(err)
+	}
+	if g.MinLevel != LevelWarn {
+		t.Errorf("MinLevel = %s, want warn", g.MinLevel)
+	}
+	if err := json.Unmarshal([]byte(`{"MinLevel":"loud"}`), &g); err == nil {
+		t.Error("Unmarshal() expected an error for an unknown level")
+	}
+}
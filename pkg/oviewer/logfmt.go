@@ -0,0 +1,166 @@
+package oviewer
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// LogfmtDelimiter is the column delimiter that splits logfmt lines
+// into key=value columns.
+const LogfmtDelimiter = "logfmt"
+
+// logfmtMaxWidth is the widest a logfmt column is padded to.
+const logfmtMaxWidth = 40
+
+// logfmtCell is a key=value pair of a logfmt line.
+// The words before the first pair are a cell without a key.
+type logfmtCell struct {
+	key  string
+	text string
+}
+
+// logfmtMode returns true if the lines are shown as logfmt columns.
+func (m *Document) logfmtMode() bool {
+	return m.ColumnMode && m.ColumnDelimiter == LogfmtDelimiter
+}
+
+// splitLogfmt splits a line into words at spaces outside double quotes.
+func splitLogfmt(s string) []string {
+	var words []string
+	start, quoted, escaped := -1, false, false
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quoted:
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case r == ' ' && !quoted:
+			if start >= 0 {
+				words = append(words, s[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, s[start:])
+	}
+	return words
+}
+
+// parseLogfmt returns the cells of a logfmt line, false if it has no pair.
+// A word that is not a pair continues the value of the previous pair.
+func parseLogfmt(line string) ([]logfmtCell, bool) {
+	var cells []logfmtCell
+	pairs := false
+	for _, w := range splitLogfmt(line) {
+		i := strings.IndexByte(w, '=')
+		if i > 0 && !strings.ContainsAny(w[:i], `"`) {
+			cells = append(cells, logfmtCell{key: w[:i], text: w})
+			pairs = true
+			continue
+		}
+		if len(cells) == 0 {
+			cells = append(cells, logfmtCell{text: w})
+			continue
+		}
+		cells[len(cells)-1].text += " " + w
+	}
+	return cells, pairs
+}
+
+// logfmtColumn returns the index of the column of the key,
+// a key seen for the first time adds a column.
+// The first column holds the words before the first pair.
+func (m *Document) logfmtColumn(key string) int {
+	if len(m.logfmtKeys) == 0 {
+		m.logfmtKeys = []string{""}
+		m.logfmtWidths = []int{0}
+	}
+	for i, k := range m.logfmtKeys {
+		if k == key {
+			return i
+		}
+	}
+	m.logfmtKeys = append(m.logfmtKeys, key)
+	m.logfmtWidths = append(m.logfmtWidths, 0)
+	return len(m.logfmtKeys) - 1
+}
+
+// renderLogfmt renders a logfmt line as columns named by key and aligned
+// with the other lines rendered. Other lines are returned unchanged.
+func (m *Document) renderLogfmt(line string) string {
+	cells, ok := parseLogfmt(line)
+	if !ok {
+		return line
+	}
+
+	texts := make(map[int]string, len(cells))
+	for _, c := range cells {
+		n := m.logfmtColumn(c.key)
+		texts[n] = c.text
+		// Wider text overflows its column.
+		width := min(runewidth.StringWidth(c.text), logfmtMaxWidth)
+		if width > m.logfmtWidths[n] {
+			m.logfmtWidths[n] = width
+			// The lines rendered narrower are rendered again.
+			m.columnWidened = true
+			atomic.StoreInt32(&m.changed, 1)
+		}
+	}
+
+	var b strings.Builder
+	for n, width := range m.logfmtWidths {
+		if width == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		text := texts[n]
+		b.WriteString(text)
+		if pad := width - runewidth.StringWidth(text); pad > 0 {
+			b.WriteString(strings.Repeat(" ", pad))
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// logfmtRange returns the range of the contents of the column,
+// and the key of the column. The columns without width are skipped.
+func (m *Document) logfmtRange(columnNum int) (int, int, string, bool) {
+	x, n := 0, 0
+	for i, width := range m.logfmtWidths {
+		if width == 0 {
+			continue
+		}
+		if n == columnNum {
+			return x, x + width, m.logfmtKeys[i], true
+		}
+		x += width + 1
+		n++
+	}
+	return 0, 0, "", false
+}
+
+// isLogfmtLine returns true if the line is shown as logfmt columns.
+func (m *Document) isLogfmtLine(lN int) bool {
+	if lN < 0 || lN >= m.BufEndNum() {
+		return false
+	}
+	_, ok := parseLogfmt(m.GetLine(lN))
+	return ok
+}
+
+// resetLogfmt forgets the columns of the logfmt lines.
+func (m *Document) resetLogfmt() {
+	m.logfmtKeys = nil
+	m.logfmtWidths = nil
+}
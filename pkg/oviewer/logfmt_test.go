@@ -0,0 +1,75 @@
+package oviewer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_splitLogfmt(t *testing.T) {
+	got := splitLogfmt(`time=10:00 level=info  msg="a \"quoted\" message" done`)
+	want := []string{`time=10:00`, `level=info`, `msg="a \"quoted\" message"`, `done`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitLogfmt() = %q, want %q", got, want)
+	}
+}
+
+func TestDocument_renderLogfmt(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.ColumnMode = true
+	m.ColumnDelimiter = LogfmtDelimiter
+
+	lines := []string{
+		`level=info msg="started worker" id=1`,
+		`level=warning msg=slow id=22 took=3s`,
+		`not logfmt`,
+	}
+	for _, line := range lines {
+		m.renderLogfmt(line)
+	}
+
+	tests := []struct {
+		line string
+		want string
+	}{
+		{line: lines[0], want: `level=info    msg="started worker" id=1`},
+		{line: lines[1], want: `level=warning msg=slow` + strings.Repeat(" ", 13) + `id=22 took=3s`},
+		{line: `ts=1 level=info`, want: `level=info` + strings.Repeat(" ", 3+1+20+1+5+1+7+1) + `ts=1`},
+		{line: lines[2], want: lines[2]},
+	}
+	for _, tt := range tests {
+		if got := m.renderLogfmt(tt.line); got != tt.want {
+			t.Errorf("renderLogfmt(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+
+	start, end, key, ok := m.logfmtRange(1)
+	if !ok || key != "msg" || start != 14 || end != 34 {
+		t.Errorf("logfmtRange(1) = %d, %d, %q, %t, want 14, 34, msg, true", start, end, key, ok)
+	}
+}
+
+func TestDocument_renderLogfmt_wide(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.ColumnMode = true
+	m.ColumnDelimiter = LogfmtDelimiter
+
+	msg := `msg="` + strings.Repeat("x", 60) + `"`
+	line := `level=info ` + msg + ` user=bob`
+	m.renderLogfmt(line)
+	want := line
+	if got := m.renderLogfmt(line); got != want {
+		t.Errorf("renderLogfmt(%q) = %q, want %q", line, got, want)
+	}
+
+	start, end, key, ok := m.logfmtRange(1)
+	if !ok || key != "msg" || start != 11 || end != 11+logfmtMaxWidth {
+		t.Errorf("logfmtRange(1) = %d, %d, %q, %t, want 11, %d, msg, true", start, end, key, ok, 11+logfmtMaxWidth)
+	}
+}
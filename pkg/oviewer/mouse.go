@@ -24,6 +24,12 @@ func (root *Root) mouseEvent(ev *tcell.EventMouse) {
 		return
 	}
 
+	if button == tcell.ButtonSecondary && !root.mouseSelect {
+		_, y := ev.Position()
+		root.lineDetailAt(y)
+		return
+	}
+
 	if button != tcell.ButtonNone || root.mouseSelect {
 		root.selectRange(ev)
 		return
@@ -383,6 +383,9 @@ func (root *Root) moveRight() {
 // columnModeX returns the actual x from m.columnNum.
 func (root *Root) columnModeX() int {
 	m := root.Doc
+	if m.logfmtMode() && !m.JSONMode {
+		return root.logfmtModeX()
+	}
 	// m.firstLine()+10 = Maximum columnMode target.
 	for i := 0; i < m.firstLine()+10; i++ {
 		lc, err := m.contentsLN(m.topLN+m.firstLine()+i, m.TabWidth)
@@ -414,6 +417,35 @@ func (root *Root) columnModeX() int {
 	return 0
 }
 
+// logfmtModeX returns the actual x from m.columnNum of the logfmt columns.
+func (root *Root) logfmtModeX() int {
+	m := root.Doc
+	if m.columnNum < 0 {
+		m.columnNum = 0
+	}
+	sx, ex, key, ok := m.logfmtRange(m.columnNum)
+	for !ok && m.columnNum > 0 {
+		m.columnNum--
+		sx, ex, key, ok = m.logfmtRange(m.columnNum)
+	}
+	if !ok {
+		return 0
+	}
+	if key == "" {
+		key = "(prefix)"
+	}
+	root.setMessagef("Column %s", key)
+
+	ex += 10
+	if root.vWidth > ex {
+		return 0
+	}
+	if ex-root.vWidth > 0 {
+		return ex - root.vWidth
+	}
+	return sx
+}
+
 // Move to the left by half a screen.
 // Called from a EventKey.
 func (root *Root) moveHfLeft() {
@@ -30,6 +30,10 @@ type Root struct {
 	helpDoc *Document
 	// log
 	logDoc *Document
+	// panel
+	panelDoc *Document
+	// fields of the line detail
+	detailFields []detailField
 
 	// DocList
 	DocList    []*Document
@@ -57,6 +61,8 @@ type Root struct {
 	keyConfig *cbind.Configuration
 	// inputKeyConfig contains the binding settings for the key.
 	inputKeyConfig *cbind.Configuration
+	// handlerKeys contains the keys of the handlers set by SetKeyHandler.
+	handlerKeys KeyBind
 
 	// message is the message to display.
 	message string
@@ -101,6 +107,9 @@ type Root struct {
 	// cancelKeys represents the cancellation key string.
 	cancelKeys []string
 
+	// highlighters are the compiled highlight rules.
+	highlighters []highlighter
+
 	log     func(arv ...interface{})
 	watcher *fsnotify.Watcher
 }
@@ -143,11 +152,31 @@ type general struct {
 	// SectionDelimiter is a section delimiter.
 	SectionDelimiter string
 	// SectionDelimiterReg is a section delimiter.
-	SectionDelimiterReg *regexp.Regexp
+	SectionDelimiterReg *regexp.Regexp `json:"-"`
 	// SectionStartPosition is a section start position.
 	SectionStartPosition int
+	// Filters is the filter stack.
+	Filters []FilterRule
+	// MinLevel hides the lines of a lower level.
+	MinLevel Level
+	// JSONMode renders JSON lines as columns.
+	JSONMode bool
+	// JSONFields are the columns of the JSON mode.
+	JSONFields []string
+	// TimeMode is how the leading timestamps are displayed.
+	TimeMode TimeMode
+	// TimeZone is the zone of the timestamps displayed, such as Europe/Berlin.
+	// Empty is the local zone.
+	TimeZone string
+	// GapThreshold marks the gaps between timestamps longer than
+	// this number of seconds. 0 is off.
+	GapThreshold int
 }
 
+// General is the view settings of a document,
+// as in Config.General and Config.Mode.
+type General = general
+
 // Config represents the settings of ov.
 type Config struct {
 	// StyleAlternate is a style that applies line by line.
@@ -170,6 +199,16 @@ type Config struct {
 	StyleMarkLine OVStyle
 	// StyleSectionLine is a style that section delimiter line.
 	StyleSectionLine OVStyle
+	// StyleLevelError is the style of error lines.
+	StyleLevelError OVStyle
+	// StyleLevelWarn is the style of warning lines.
+	StyleLevelWarn OVStyle
+	// StyleLevelInfo is the style of info lines.
+	StyleLevelInfo OVStyle
+	// StyleLevelDebug is the style of debug lines.
+	StyleLevelDebug OVStyle
+	// StyleGapLine is the style of the line before a time gap.
+	StyleGapLine OVStyle
 
 	// General represents the general behavior.
 	General general
@@ -201,6 +240,9 @@ type Config struct {
 	// KeyBinding
 	Keybind map[string][]string
 
+	// Highlights are the styles of the patterns in the body.
+	Highlights []HighlightRule
+
 	// Old setting.
 
 	// Deprecated: Alternating background color.
@@ -262,6 +304,10 @@ const (
 	Help
 	// LogDoc is Error screen mode.
 	LogDoc
+	// Panel is an additional document screen mode.
+	Panel
+	// Detail is the line detail screen mode.
+	Detail
 )
 
 const MaxWriteLog int = 10
@@ -287,6 +333,14 @@ var (
 	ErrSignalCatch = errors.New("signal catch")
 	// ErrAlreadyClose indicates that it is already closed.
 	ErrAlreadyClose = errors.New("already closed")
+	// ErrInvalidRange indicates an invalid range of lines.
+	ErrInvalidRange = errors.New("invalid range")
+	// ErrInvalidHighlight indicates an invalid highlight pattern.
+	ErrInvalidHighlight = errors.New("invalid highlight")
+	// ErrInvalidLevel indicates an invalid log level.
+	ErrInvalidLevel = errors.New("invalid level")
+	// ErrInvalidTime indicates an invalid time.
+	ErrInvalidTime = errors.New("invalid time")
 )
 
 // This is a function of tcell.NewScreen but can be replaced with mock.
@@ -304,6 +358,7 @@ func NewOviewer(docs ...*Document) (*Root, error) {
 	root.Config = NewConfig()
 	root.keyConfig = cbind.NewConfiguration()
 	root.inputKeyConfig = cbind.NewConfiguration()
+	root.handlerKeys = make(KeyBind)
 	root.DocList = append(root.DocList, docs...)
 	root.Doc = root.DocList[0]
 	root.input = NewInput()
@@ -359,6 +414,18 @@ func NewConfig() Config {
 		StyleSectionLine: OVStyle{
 			Background: "green",
 		},
+		StyleLevelError: OVStyle{
+			Foreground: "red",
+		},
+		StyleLevelWarn: OVStyle{
+			Foreground: "yellow",
+		},
+		StyleLevelDebug: OVStyle{
+			Dim: true,
+		},
+		StyleGapLine: OVStyle{
+			Underline: true,
+		},
 		General: general{
 			TabWidth:             8,
 			MarkStyleWidth:       1,
@@ -506,13 +573,16 @@ func (root *Root) setKeyConfig() (map[string][]string, error) {
 }
 
 // SetKeyHandler assigns a new key handler.
+// The keys are shown in the help screen under the name.
 func (root *Root) SetKeyHandler(name string, keys []string, handler func()) error {
+	root.handlerKeys[name] = keys
 	return setHandler(root.keyConfig, name, keys, handler)
 }
 
 // Run starts the terminal pager.
 func (root *Root) Run() error {
 	defer root.Close()
+	defer root.saveHistory()
 
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -529,6 +599,9 @@ func (root *Root) Run() error {
 	if err != nil {
 		return err
 	}
+	for name, keys := range root.handlerKeys {
+		keyBind[name] = keys
+	}
 	help, err := NewHelp(keyBind)
 	if err != nil {
 		return err
@@ -553,6 +626,10 @@ func (root *Root) Run() error {
 	}
 
 	root.setModeConfig()
+	if err := root.setHighlighters(); err != nil {
+		root.log(err)
+	}
+	root.applyFilters()
 
 	root.ViewSync()
 	// Exit if fits on screen
@@ -628,6 +705,11 @@ func (root *Root) setMessage(msg string) {
 	root.Show()
 }
 
+// SetMessage displays the message in the status line.
+func (root *Root) SetMessage(msg string) {
+	root.setMessage(msg)
+}
+
 func (root *Root) setMessagef(format string, a ...interface{}) {
 	msg := fmt.Sprintf(format, a...)
 	root.setMessage(msg)
@@ -723,6 +805,27 @@ func overwriteGeneral(a general, b general) general {
 	if b.SectionStartPosition != 0 {
 		a.SectionStartPosition = b.SectionStartPosition
 	}
+	if b.Filters != nil {
+		a.Filters = b.Filters
+	}
+	if b.MinLevel != LevelUnknown {
+		a.MinLevel = b.MinLevel
+	}
+	if b.JSONMode {
+		a.JSONMode = b.JSONMode
+	}
+	if b.JSONFields != nil {
+		a.JSONFields = b.JSONFields
+	}
+	if b.TimeMode != TimeOriginal {
+		a.TimeMode = b.TimeMode
+	}
+	if b.TimeZone != "" {
+		a.TimeZone = b.TimeZone
+	}
+	if b.GapThreshold != 0 {
+		a.GapThreshold = b.GapThreshold
+	}
 	return a
 }
 
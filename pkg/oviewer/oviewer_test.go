@@ -228,3 +228,64 @@ func Test_applyStyle(t *testing.T) {
 		})
 	}
 }
+
+func TestRoot_setViewMode(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := NewOviewer(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.Config.Mode = map[string]general{
+		"nginx": {ColumnMode: true, ColumnDelimiter: " "},
+	}
+
+	// A mode of the rules is applied, but not taken as chosen by hand.
+	root.applyViewMode("nginx")
+	if !m.ColumnMode || m.ViewMode() != "" {
+		t.Errorf("applyViewMode() ColumnMode = %v, ViewMode() = %q", m.ColumnMode, m.ViewMode())
+	}
+
+	root.setViewMode("nginx")
+	if got := m.ViewMode(); got != "nginx" {
+		t.Errorf("setViewMode() ViewMode() = %q, want %q", got, "nginx")
+	}
+
+	root.setViewMode("missing")
+	if got := m.ViewMode(); got != "nginx" {
+		t.Errorf("setViewMode(missing) ViewMode() = %q, want %q", got, "nginx")
+	}
+}
+
+func Test_overwriteGeneral(t *testing.T) {
+	a := general{
+		Filters:  []FilterRule{{Pattern: "health", Exclude: true}},
+		MinLevel: LevelWarn,
+		JSONMode: true,
+		TimeMode: TimeShort,
+	}
+
+	// A mode that does not set them keeps the filters, JSON and time mode.
+	got := overwriteGeneral(a, general{ColumnMode: true})
+	want := a
+	want.ColumnMode = true
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("overwriteGeneral() = %+v, want %+v", got, want)
+	}
+
+	b := general{
+		Filters:  []FilterRule{{Pattern: "error"}},
+		MinLevel: LevelError,
+		TimeMode: TimeRelative,
+	}
+	got = overwriteGeneral(a, b)
+	if !reflect.DeepEqual(got.Filters, b.Filters) || got.MinLevel != LevelError || got.TimeMode != TimeRelative {
+		t.Errorf("overwriteGeneral() = %+v, want the settings of the mode", got)
+	}
+}
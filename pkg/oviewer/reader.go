@@ -2,6 +2,7 @@ package oviewer
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -231,6 +232,7 @@ func (m *Document) append(lines ...string) {
 	m.mu.Lock()
 	for _, line := range lines {
 		m.lines = append(m.lines, line)
+		m.anchorLine(m.endNum)
 		m.endNum++
 	}
 	m.mu.Unlock()
@@ -252,9 +254,19 @@ func (m *Document) appendFormFeed() {
 }
 
 // reload will read again.
+// Generated documents are generated again.
 // Regular files are reopened and reread increase.
 // The pipe will reset what it has read.
 func (m *Document) reload() error {
+	if m.generate != nil {
+		lines, err := m.generateLines()
+		if err != nil {
+			return err
+		}
+		m.setLines(lines)
+		return nil
+	}
+
 	if (m.file == os.Stdin && m.BufEOF()) || !m.seekable && m.checkClose() {
 		return fmt.Errorf("%w %s", ErrAlreadyClose, m.FileName)
 	}
@@ -285,12 +297,28 @@ func (m *Document) reload() error {
 	return m.ReadFile(m.FileName)
 }
 
+// generateLines returns the output of the generate function.
+func (m *Document) generateLines() ([]string, error) {
+	var buf bytes.Buffer
+	if err := m.generate(&buf); err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"), nil
+}
+
+// setLines replaces all lines.
+func (m *Document) setLines(lines []string) {
+	m.reset()
+	m.append(lines...)
+}
+
 // reset clears all lines.
 func (m *Document) reset() {
 	m.mu.Lock()
 	m.endNum = 0
 	m.lines = m.lines[:0]
 	m.mu.Unlock()
+	m.resetLevels()
 	atomic.StoreInt32(&m.changed, 1)
 	m.ClearCache()
 }
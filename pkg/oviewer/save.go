@@ -0,0 +1,147 @@
+package oviewer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// saveOption represents the options of the save input "path [N-M|marked] [header]".
+type saveOption struct {
+	fileName string
+	lines    []int
+	header   bool
+}
+
+// saveAs saves the current document to the file specified by the input.
+func (root *Root) saveAs(input string) {
+	opt, err := root.parseSaveInput(input)
+	if err != nil {
+		root.setMessagef("Save: %s", err)
+		return
+	}
+
+	if err := root.Doc.save(opt); err != nil {
+		root.setMessagef("Save %s: %s", opt.fileName, err)
+		return
+	}
+	root.setMessagef("Saved %d lines to %s", len(opt.lines), opt.fileName)
+}
+
+// parseSaveInput parses the input of save as.
+// The line numbers are the displayed line numbers.
+func (root *Root) parseSaveInput(input string) (saveOption, error) {
+	m := root.Doc
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return saveOption{}, ErrMissingFile
+	}
+
+	opt := saveOption{fileName: fields[0]}
+	start, end := 0, m.BufEndNum()-1
+	for _, f := range fields[1:] {
+		switch {
+		case f == "header":
+			opt.header = true
+		case f == "marked":
+			if len(m.marked) == 0 {
+				return opt, fmt.Errorf("%w: no marks", ErrInvalidRange)
+			}
+			opt.lines = append(opt.lines, m.marked...)
+			sort.Ints(opt.lines)
+		default:
+			s, e, err := lineRange(f)
+			if err != nil {
+				return opt, err
+			}
+			start = max(s-1+m.firstLine(), 0)
+			end = min(e-1+m.firstLine(), m.BufEndNum()-1)
+		}
+	}
+
+	if opt.lines == nil {
+		for n := start; n <= end; n++ {
+			opt.lines = append(opt.lines, n)
+		}
+	}
+	return opt, nil
+}
+
+// lineRange parses N-M or N.
+func lineRange(str string) (int, int, error) {
+	se := strings.SplitN(str, "-", 2)
+	start, err := strconv.Atoi(se[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("%w: %s", ErrInvalidRange, str)
+	}
+	if len(se) == 1 {
+		return start, start, nil
+	}
+	end, err := strconv.Atoi(se[1])
+	if err != nil || end < start {
+		return 0, 0, fmt.Errorf("%w: %s", ErrInvalidRange, str)
+	}
+	return start, end, nil
+}
+
+// save writes the lines of the document to a file.
+// The file is compressed according to the extension.
+// The file is removed if it cannot be written completely.
+func (m *Document) save(opt saveOption) error {
+	f, err := os.Create(opt.fileName)
+	if err != nil {
+		return err
+	}
+
+	if err := m.writeSave(f, opt); err != nil {
+		f.Close()
+		os.Remove(opt.fileName)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(opt.fileName)
+		return err
+	}
+	return nil
+}
+
+// writeSave writes the lines compressed according to the extension.
+func (m *Document) writeSave(f io.Writer, opt saveOption) error {
+	w, err := CompressedWriter(CompressType(opt.fileName), f)
+	if err != nil {
+		return err
+	}
+
+	if opt.header {
+		m.writeSaveHeader(w, opt.lines)
+	}
+	for _, n := range opt.lines {
+		if _, err := fmt.Fprintln(w, m.GetLine(n)); err != nil {
+			w.Close()
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// writeSaveHeader writes the metadata of the document as a comment header.
+//
+//goland:noinspection GoUnhandledErrorResult
+func (m *Document) writeSaveHeader(w io.Writer, lines []int) {
+	for _, meta := range m.Metadata {
+		fmt.Fprintf(w, "# %s\n", meta)
+	}
+	if len(lines) > 0 {
+		first, last := lines[0], lines[len(lines)-1]
+		if from, ok := LineTime(m.GetLine(first)); ok {
+			if to, ok := LineTime(m.GetLine(last)); ok {
+				fmt.Fprintf(w, "# time range: %s - %s\n", from.Format(time.RFC3339Nano), to.Format(time.RFC3339Nano))
+			}
+		}
+	}
+	fmt.Fprintf(w, "# saved: %s\n", time.Now().Format(time.RFC3339))
+}
@@ -0,0 +1,119 @@
+package oviewer
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_lineRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		str       string
+		wantStart int
+		wantEnd   int
+		wantErr   bool
+	}{
+		{
+			name:      "single",
+			str:       "10",
+			wantStart: 10,
+			wantEnd:   10,
+		},
+		{
+			name:      "range",
+			str:       "10-20",
+			wantStart: 10,
+			wantEnd:   20,
+		},
+		{
+			name:    "reverse",
+			str:     "20-10",
+			wantErr: true,
+		},
+		{
+			name:    "invalid",
+			str:     "a-b",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := lineRange(tt.str)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("lineRange() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("lineRange() = %v, %v, want %v, %v", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestDocument_save(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		opt      saveOption
+		want     string
+	}{
+		{
+			name:     "plain",
+			fileName: "test.log",
+			opt:      saveOption{lines: []int{0, 2}},
+			want:     "a\nc\n",
+		},
+		{
+			name:     "gzip",
+			fileName: "test.log.gz",
+			opt:      saveOption{lines: []int{1}},
+			want:     "b\n",
+		},
+		{
+			name:     "zstd",
+			fileName: "test.log.zst",
+			opt:      saveOption{lines: []int{0, 1, 2}},
+			want:     "a\nb\nc\n",
+		},
+		{
+			name:     "xz header",
+			fileName: "test.log.xz",
+			opt:      saveOption{lines: []int{0}, header: true},
+			want:     "# id: test\n# saved: ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.append("a", "b", "c")
+			m.Metadata = []string{"id: test"}
+
+			tt.opt.fileName = filepath.Join(t.TempDir(), tt.fileName)
+			if err := m.save(tt.opt); err != nil {
+				t.Fatal(err)
+			}
+
+			f, err := os.Open(tt.opt.fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			cFormat, r := uncompressedReader(f)
+			if cFormat != CompressType(tt.fileName) {
+				t.Errorf("compress format = %v, want %v", cFormat, CompressType(tt.fileName))
+			}
+			b, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(b); len(got) < len(tt.want) || got[:len(tt.want)] != tt.want {
+				t.Errorf("Document.save() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
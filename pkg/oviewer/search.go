@@ -79,6 +79,11 @@ func NewSearcher(word string, searchReg *regexp.Regexp, caseSensitive bool, rege
 	}
 }
 
+// NewWordSearcher returns the Searcher the search prompt uses for word.
+func NewWordSearcher(word string, caseSensitive bool, regexpSearch bool) Searcher {
+	return NewSearcher(word, regexpCompile(word, caseSensitive), caseSensitive, regexpSearch)
+}
+
 // regexpCompile is regexp.Compile the search string.
 func regexpCompile(r string, caseSensitive bool) *regexp.Regexp {
 	if !caseSensitive {
@@ -221,6 +226,17 @@ func (root *Root) setSearcher(word string, caseSensitive bool) Searcher {
 	return NewSearcher(root.searchWord, root.searchReg, caseSensitive, root.Config.RegexpSearch)
 }
 
+// LastSearch returns the current search word.
+func (root *Root) LastSearch() string {
+	return root.searchWord
+}
+
+// SetLastSearch makes word the current search, or clears it if empty.
+// The search history is kept in the history file and does not change.
+func (root *Root) SetLastSearch(word string) {
+	root.setSearcher(word, root.CaseSensitive)
+}
+
 // searchMove searches forward/backward and moves to the nearest matching line.
 func (root *Root) searchMove(ctx context.Context, forward bool, lN int, searcher Searcher) {
 	if searcher == nil {
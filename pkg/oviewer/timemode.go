@@ -0,0 +1,129 @@
+package oviewer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dimcz/viewer/pkg/timestamp"
+)
+
+// TimeMode is how the leading timestamp of the lines is displayed.
+// Searching and saving use the original text.
+type TimeMode string
+
+const (
+	// TimeOriginal displays the timestamp as it is.
+	TimeOriginal TimeMode = ""
+	// TimeZone displays the timestamp in the local or the configured zone.
+	TimeZone TimeMode = "zone"
+	// TimeShort displays the time of day in the zone, with milliseconds.
+	TimeShort TimeMode = "short"
+	// TimeRelative displays the time before now.
+	TimeRelative TimeMode = "relative"
+	// TimeDelta displays the time since the previous line with a timestamp.
+	TimeDelta TimeMode = "delta"
+)
+
+// timeModes is the order the time modes are cycled in.
+var timeModes = []TimeMode{TimeOriginal, TimeZone, TimeShort, TimeRelative, TimeDelta}
+
+// timeDurationWidth is the width the relative and delta times are padded to.
+const timeDurationWidth = 10
+
+// location returns the location of the time zone of the document,
+// the local time if it has none or it is unknown.
+func (m *Document) location() *time.Location {
+	if m.timeLoc != nil && m.timeLocZone == m.TimeZone {
+		return m.timeLoc
+	}
+	m.timeLoc, m.timeLocZone = time.Local, m.TimeZone
+	if m.TimeZone != "" {
+		if loc, err := time.LoadLocation(m.TimeZone); err == nil {
+			m.timeLoc = loc
+		}
+	}
+	return m.timeLoc
+}
+
+// renderTime rewrites the timestamp the line starts with in the time mode.
+// Other lines are returned unchanged.
+func (m *Document) renderTime(lN int, line string) string {
+	now := time.Now()
+	t, n, ok := timestamp.Leading(line, now)
+	if !ok {
+		return line
+	}
+
+	var s string
+	switch m.TimeMode {
+	case TimeZone:
+		s = t.In(m.location()).Format("2006-01-02T15:04:05.000Z07:00")
+	case TimeShort:
+		s = t.In(m.location()).Format("15:04:05.000")
+	case TimeRelative:
+		s = fmt.Sprintf("%*s", timeDurationWidth, "-"+formatDuration(now.Sub(t)))
+	case TimeDelta:
+		d := time.Duration(0)
+		if prev, ok := m.lineTime(lN - 1); ok {
+			d = t.Sub(prev)
+		}
+		sign := "+"
+		if d < 0 {
+			sign, d = "-", -d
+		}
+		s = fmt.Sprintf("%*s", timeDurationWidth, sign+formatDuration(d))
+	default:
+		return line
+	}
+	return s + line[n:]
+}
+
+// formatDuration formats a duration in milliseconds below a minute,
+// in seconds below a day and in days, hours and minutes above.
+func formatDuration(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+	switch {
+	case d < time.Minute:
+		return d.Round(time.Millisecond).String()
+	case d < 24*time.Hour:
+		return d.Round(time.Second).String()
+	}
+	d = d.Round(time.Minute)
+	days := d / (24 * time.Hour)
+	s := (d - days*24*time.Hour).String()
+	return fmt.Sprintf("%dd%s", days, strings.TrimSuffix(s, "0s"))
+}
+
+// cycleTimeMode displays the timestamps in the next time mode.
+func (root *Root) cycleTimeMode() {
+	mode := timeModes[0]
+	for i, t := range timeModes {
+		if t == root.Doc.TimeMode {
+			mode = timeModes[(i+1)%len(timeModes)]
+		}
+	}
+	root.setTimeMode(mode)
+
+	switch mode {
+	case TimeOriginal:
+		root.setMessage("Time original")
+	case TimeZone, TimeShort:
+		root.setMessagef("Time %s %s", mode, root.Doc.location())
+	default:
+		root.setMessagef("Time %s", mode)
+	}
+}
+
+// setTimeMode sets the time mode of the document, and of its parent if filtered.
+func (root *Root) setTimeMode(mode TimeMode) {
+	for _, m := range []*Document{root.Doc, root.Doc.parent} {
+		if m == nil {
+			continue
+		}
+		m.TimeMode = mode
+		m.ClearCache()
+	}
+}
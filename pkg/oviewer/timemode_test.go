@@ -0,0 +1,62 @@
+package oviewer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDocument_renderTime(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.append(
+		"2026-10-18T14:05:00.123456789Z start",
+		"    at main.go:10",
+		"2026-10-18T14:05:01.623456789Z stop",
+		"no timestamp 2026-10-18T14:05:00Z",
+	)
+	m.TimeZone = "Asia/Tokyo"
+	tests := []struct {
+		name string
+		mode TimeMode
+		lN   int
+		want string
+	}{
+		{name: "original", mode: TimeOriginal, lN: 0, want: "2026-10-18T14:05:00.123456789Z start"},
+		{name: "zone", mode: TimeZone, lN: 0, want: "2026-10-18T23:05:00.123+09:00 start"},
+		{name: "short", mode: TimeShort, lN: 2, want: "23:05:01.623 stop"},
+		{name: "delta first", mode: TimeDelta, lN: 0, want: strings.Repeat(" ", 7) + "+0s start"},
+		{name: "delta", mode: TimeDelta, lN: 2, want: strings.Repeat(" ", 5) + "+1.5s stop"},
+		{name: "not leading", mode: TimeShort, lN: 3, want: "no timestamp 2026-10-18T14:05:00Z"},
+		{name: "none", mode: TimeShort, lN: 1, want: "    at main.go:10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.TimeMode = tt.mode
+			if got := m.renderTime(tt.lN, m.GetLine(tt.lN)); got != tt.want {
+				t.Errorf("Document.renderTime() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 1500 * time.Millisecond, want: "1.5s"},
+		{d: 5*time.Minute + 3*time.Second + 400*time.Millisecond, want: "5m3s"},
+		{d: 50*time.Hour + 30*time.Minute, want: "2d2h30m"},
+		{d: -2 * time.Second, want: "2s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := formatDuration(tt.d); got != tt.want {
+				t.Errorf("formatDuration() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
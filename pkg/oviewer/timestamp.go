@@ -0,0 +1,128 @@
+package oviewer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dimcz/viewer/pkg/timestamp"
+)
+
+// timeClockReg matches a time of day.
+var timeClockReg = regexp.MustCompile(`^\d{1,2}:\d\d(?::\d\d(?:\.\d+)?)?$`)
+
+// LineTime returns the timestamp of a log line.
+// Timestamps without a zone are in the local time.
+func LineTime(line string) (time.Time, bool) {
+	return timestamp.Find(stripEscapeSequence(line), time.Now())
+}
+
+// parseGotoTime parses the time of the goto input.
+// It is a time of day on the day of ref in loc, the zone the times
+// are displayed in, a timestamp, a date or a duration before now (e.g. -15m).
+func parseGotoTime(s string, now time.Time, ref time.Time, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "-") {
+		if d, err := time.ParseDuration(s[1:]); err == nil {
+			return now.Add(-d), nil
+		}
+	}
+	if timeClockReg.MatchString(s) {
+		layout := "15:04:05.999999999"
+		if strings.Count(s, ":") == 1 {
+			layout = "15:04"
+		}
+		c, err := time.Parse(layout, s)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidTime, s)
+		}
+		if ref.IsZero() {
+			ref = now
+		}
+		y, mo, d := ref.In(loc).Date()
+		return time.Date(y, mo, d, c.Hour(), c.Minute(), c.Second(), c.Nanosecond(), loc), nil
+	}
+	if t, ok := timestamp.Find(s, now); ok {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidTime, s)
+}
+
+// isGotoTime reports whether the goto input is a time rather than a line number.
+// Line numbers, also negative ones and with the number of a wrapped line, are not.
+func isGotoTime(s string) bool {
+	lN := strings.SplitN(strings.TrimSpace(s), ".", 2)[0]
+	if _, err := strconv.Atoi(lN); err == nil {
+		return false
+	}
+	return strings.ContainsAny(s, ":-")
+}
+
+// timeLookBack is the number of lines a line without
+// a timestamp looks back for the timestamp it takes.
+const timeLookBack = 100
+
+// lineTime returns the timestamp of the line, or of the line with
+// a timestamp before it, such as the lines of a stack trace.
+func (m *Document) lineTime(lN int) (time.Time, bool) {
+	for i := lN; i >= 0 && i >= lN-timeLookBack; i-- {
+		if t, ok := LineTime(m.GetLine(i)); ok {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// nextTime returns the first line from lN before end with a timestamp.
+func (m *Document) nextTime(lN int, end int) (int, time.Time, bool) {
+	for ; lN < end; lN++ {
+		if t, ok := LineTime(m.GetLine(lN)); ok {
+			return lN, t, true
+		}
+	}
+	return 0, time.Time{}, false
+}
+
+// searchTime returns the first line with a timestamp at or after t.
+// The timestamps are taken as ascending and are searched by bisection,
+// the lines without a timestamp go with the line before them.
+func (m *Document) searchTime(t time.Time) (int, bool) {
+	end := m.BufEndNum()
+	lo, hi := m.firstLine(), end
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		n, lt, ok := m.nextTime(mid, hi)
+		switch {
+		case !ok:
+			hi = mid
+		case lt.Before(t):
+			lo = n + 1
+		default:
+			hi = n
+		}
+	}
+	n, _, ok := m.nextTime(lo, end)
+	return n, ok
+}
+
+// goTime moves to the first line at or after the time of the input.
+func (root *Root) goTime(input string) {
+	ref, _ := root.Doc.lineTime(root.Doc.topLN + root.Doc.firstLine())
+	t, err := parseGotoTime(input, time.Now(), ref, root.Doc.location())
+	if err != nil {
+		root.setMessage(err.Error())
+		return
+	}
+	lN, ok := root.Doc.searchTime(t)
+	if !ok {
+		root.setMessagef("No line at or after %s", t.Format(time.RFC3339))
+		return
+	}
+	root.moveLine(lN - root.Doc.firstLine())
+	root.setMessagef("Moved to %s (line %d)", t.Format(time.RFC3339), lN+1)
+}
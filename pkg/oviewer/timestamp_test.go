@@ -0,0 +1,136 @@
+package oviewer
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_parseGotoTime(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	ref := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		input   string
+		ref     time.Time
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "clock", input: "14:05", ref: ref, want: time.Date(2026, 10, 18, 14, 5, 0, 0, time.UTC)},
+		{name: "clock seconds", input: "14:05:30", ref: ref, want: time.Date(2026, 10, 18, 14, 5, 30, 0, time.UTC)},
+		{name: "clock today", input: "14:05", want: time.Date(2026, 10, 19, 14, 5, 0, 0, time.UTC)},
+		{name: "rfc3339", input: "2026-10-18T14:05:00Z", want: time.Date(2026, 10, 18, 14, 5, 0, 0, time.UTC)},
+		{name: "duration", input: "-15m", want: now.Add(-15 * time.Minute)},
+		{name: "date", input: "2026-10-18", want: time.Date(2026, 10, 18, 0, 0, 0, 0, time.Local)},
+		{name: "invalid", input: "25:99", wantErr: true},
+		{name: "text", input: "a-b", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGotoTime(tt.input, now, tt.ref, time.UTC)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseGotoTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseGotoTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isGotoTime(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"10", false},
+		{"-5", false},
+		{"-5.2", false},
+		{"10.1", false},
+		{"14:05", true},
+		{"-15m", true},
+		{"2026-10-18", true},
+		{"2026-10-18T14:05:00Z", true},
+	}
+	for _, tt := range tests {
+		if got := isGotoTime(tt.input); got != tt.want {
+			t.Errorf("isGotoTime(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func Test_parseGotoTime_local(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+	local := time.Local
+	time.Local = berlin
+	defer func() { time.Local = local }()
+
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	// The docker timestamps are in UTC, the clock time is local as displayed.
+	// 23:30 UTC is already the next day in Berlin.
+	ref := time.Date(2026, 10, 18, 23, 30, 0, 0, time.UTC)
+	want := time.Date(2026, 10, 19, 14, 5, 0, 0, berlin)
+	for _, r := range []time.Time{ref, {}} {
+		got, err := parseGotoTime("14:05", now, r, m.location())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Equal(want) {
+			t.Errorf("parseGotoTime(14:05, ref %v) = %v, want %v", r, got, want)
+		}
+	}
+
+	m.TimeZone = "UTC"
+	got, err := parseGotoTime("14:05", now, ref, m.location())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2026, 10, 18, 14, 5, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("parseGotoTime(14:05) in UTC = %v, want %v", got, want)
+	}
+}
+
+func TestDocument_searchTime(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.append(
+		"2026-10-18T14:00:00Z start",
+		"2026-10-18T14:01:00Z request",
+		"    at main.go:10",
+		"2026-10-18T14:05:00Z request",
+		"2026-10-18T14:05:00Z response",
+		"2026-10-18T14:10:00Z stop",
+	)
+	tests := []struct {
+		name   string
+		t      string
+		want   int
+		wantOk bool
+	}{
+		{name: "before", t: "2026-10-18T13:00:00Z", want: 0, wantOk: true},
+		{name: "exact", t: "2026-10-18T14:05:00Z", want: 3, wantOk: true},
+		{name: "between", t: "2026-10-18T14:02:00Z", want: 3, wantOk: true},
+		{name: "last", t: "2026-10-18T14:06:00Z", want: 5, wantOk: true},
+		{name: "after", t: "2026-10-18T15:00:00Z", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := time.Parse(time.RFC3339, tt.t)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, ok := m.searchTime(target)
+			if ok != tt.wantOk || (ok && got != tt.want) {
+				t.Errorf("Document.searchTime() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
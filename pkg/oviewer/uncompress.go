@@ -62,6 +62,12 @@ func (c Compressed) String() string {
 	return "UNCOMPRESSED"
 }
 
+// UncompressedReader returns a reader that uncompresses the detected format.
+func UncompressedReader(reader io.Reader) io.Reader {
+	_, r := uncompressedReader(reader)
+	return r
+}
+
 func uncompressedReader(reader io.Reader) (Compressed, io.Reader) {
 	buf := [7]byte{}
 	n, err := io.ReadAtLeast(reader, buf[:], len(buf))
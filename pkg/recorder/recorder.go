@@ -0,0 +1,403 @@
+package recorder
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/bytefmt"
+	"github.com/dimcz/viewer/pkg/logger"
+	"github.com/dimcz/viewer/pkg/oviewer"
+	"github.com/dimcz/viewer/pkg/timestamp"
+	"github.com/pkg/errors"
+)
+
+const (
+	fileExt    = ".log"
+	timeLayout = "20060102T150405.000000"
+
+	// tailSize is how much of an existing record file is read to find
+	// the last recorded timestamp.
+	tailSize = 64 * 1024
+)
+
+var ErrUnknownCompression = errors.New("unknown compression")
+
+type Recorder struct {
+	dir      string
+	size     uint64
+	interval time.Duration
+	compress oviewer.Compressed
+
+	log *logger.Logger
+
+	mu    sync.Mutex
+	files map[string]*File
+	wg    sync.WaitGroup
+}
+
+func New(log *logger.Logger, dir, size string, interval time.Duration, compress string) (*Recorder, error) {
+	r := &Recorder{
+		dir:      dir,
+		interval: interval,
+		log:      log,
+		files:    make(map[string]*File),
+	}
+
+	if size != "" {
+		s, err := bytefmt.ToBytes(size)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse record size")
+		}
+
+		r.size = s
+	}
+
+	if compress != "" {
+		r.compress = oviewer.CompressType("." + compress)
+		if r.compress == oviewer.UNCOMPRESSED {
+			return nil, errors.Wrap(ErrUnknownCompression, compress)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, errors.Wrap(err, "failed to create record directory")
+	}
+
+	return r, nil
+}
+
+// Attach returns the writer recording one attach to the stream name.
+// The stream that is shown is written to it as well, so every stream
+// is read once. Lines an earlier attach already recorded, judged by
+// their timestamps, are skipped, so the tail sent on every attach is
+// recorded once; docker and kube logs are recorded with --timestamps
+// for that. The writer never fails, so a broken record file does
+// not stop viewing.
+func (r *Recorder) Attach(name string) (io.Writer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.files[name]
+	if !ok {
+		f = &File{
+			rec:  r,
+			path: filepath.Join(r.dir, fileName(name)+fileExt),
+		}
+
+		if err := f.open(); err != nil {
+			return nil, err
+		}
+
+		f.last = lastRecorded(f.path)
+		r.files[name] = f
+	}
+
+	last := f.lastTime()
+
+	return &attach{
+		file: f,
+		log:  r.log,
+		last: last,
+		skip: !last.IsZero(),
+		old:  true,
+	}, nil
+}
+
+func (r *Recorder) Close() {
+	r.mu.Lock()
+	for name, f := range r.files {
+		r.log.LogOnErr(f.Close())
+		delete(r.files, name)
+	}
+	r.mu.Unlock()
+
+	r.wg.Wait()
+}
+
+func (r *Recorder) archive(path string) {
+	r.wg.Add(1)
+
+	go func() {
+		defer r.wg.Done()
+
+		if err := compressFile(path, r.compress); err != nil {
+			r.log.Error("failed to compress ", path, ": ", err)
+		}
+	}()
+}
+
+// attach writes one attach of a stream to its record file.
+type attach struct {
+	file *File
+	log  *logger.Logger
+
+	last time.Time
+	skip bool
+	old  bool
+	buf  []byte
+	fail bool
+}
+
+func (a *attach) Write(p []byte) (int, error) {
+	data := p
+
+	if a.skip {
+		a.buf = append(a.buf, p...)
+		data = a.drop()
+	}
+
+	if len(data) == 0 || a.fail {
+		return len(p), nil
+	}
+
+	if _, err := a.file.Write(data); err != nil {
+		a.log.Error("failed to record ", a.file.path, ": ", err)
+		a.fail = true
+	}
+
+	return len(p), nil
+}
+
+// drop removes the complete lines of buf recorded by an earlier attach.
+// Lines without a timestamp follow the line before them. Once a new
+// line is found, the rest of buf is returned and nothing more is skipped.
+func (a *attach) drop() []byte {
+	for {
+		i := bytes.IndexByte(a.buf, '\n')
+		if i < 0 {
+			return nil
+		}
+
+		if t, ok := timestamp.Line(string(a.buf[:i])); ok {
+			a.old = !t.After(a.last)
+		}
+
+		if !a.old {
+			data := a.buf
+			a.buf = nil
+			a.skip = false
+
+			return data
+		}
+
+		a.buf = a.buf[i+1:]
+	}
+}
+
+type File struct {
+	rec  *Recorder
+	path string
+
+	mu      sync.Mutex
+	fd      *os.File
+	size    uint64
+	created time.Time
+	last    time.Time
+}
+
+func (f *File) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.fd == nil {
+		return 0, os.ErrClosed
+	}
+
+	if f.needRotate(len(p)) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.fd.Write(p)
+	f.size += uint64(n)
+
+	if t, ok := lastTime(p[:n]); ok {
+		f.last = t
+	}
+
+	return n, err
+}
+
+func (f *File) lastTime() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.last
+}
+
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.fd == nil {
+		return nil
+	}
+
+	err := f.fd.Close()
+	f.fd = nil
+
+	return err
+}
+
+func (f *File) open() error {
+	fd, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return errors.Wrap(err, "failed to open record file")
+	}
+
+	fi, err := fd.Stat()
+	if err != nil {
+		_ = fd.Close()
+
+		return errors.Wrap(err, "failed to stat record file")
+	}
+
+	f.fd = fd
+	f.size = uint64(fi.Size())
+	f.created = time.Now()
+
+	return nil
+}
+
+func (f *File) needRotate(n int) bool {
+	if f.size == 0 {
+		return false
+	}
+
+	if f.rec.size > 0 && f.size+uint64(n) > f.rec.size {
+		return true
+	}
+
+	return f.rec.interval > 0 && time.Since(f.created) >= f.rec.interval
+}
+
+func (f *File) rotate() error {
+	if err := f.fd.Close(); err != nil {
+		return errors.Wrap(err, "failed to close record file")
+	}
+
+	rotated := strings.TrimSuffix(f.path, fileExt) + "." + time.Now().Format(timeLayout) + fileExt
+	if err := os.Rename(f.path, rotated); err != nil {
+		return errors.Wrap(err, "failed to rotate record file")
+	}
+
+	if f.rec.compress != oviewer.UNCOMPRESSED {
+		f.rec.archive(rotated)
+	}
+
+	return f.open()
+}
+
+// lastRecorded returns the last timestamp in the tail of the file at path.
+func lastRecorded(path string) time.Time {
+	fd, err := os.Open(path)
+	if err != nil {
+		return time.Time{}
+	}
+	defer fd.Close()
+
+	fi, err := fd.Stat()
+	if err != nil {
+		return time.Time{}
+	}
+
+	offset := fi.Size() - tailSize
+	if offset < 0 {
+		offset = 0
+	}
+
+	data := make([]byte, fi.Size()-offset)
+	if _, err := fd.ReadAt(data, offset); err != nil && !errors.Is(err, io.EOF) {
+		return time.Time{}
+	}
+
+	t, _ := lastTime(data)
+
+	return t
+}
+
+// lastTime returns the timestamp of the last line of data that has one.
+func lastTime(data []byte) (time.Time, bool) {
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if t, ok := timestamp.Line(lines[i]); ok {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// compressFile replaces the file at path with its compressed copy.
+// The copy is removed if it cannot be written, so only path is left.
+func compressFile(path string, cFormat oviewer.Compressed) error {
+	dstPath := path + compressExt(cFormat)
+
+	if err := writeCompressed(path, dstPath, cFormat); err != nil {
+		_ = os.Remove(dstPath)
+
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+func writeCompressed(path, dstPath string, cFormat oviewer.Compressed) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		return err
+	}
+
+	w, err := oviewer.CompressedWriter(cFormat, dst)
+	if err != nil {
+		_ = dst.Close()
+
+		return err
+	}
+
+	if _, err := io.Copy(w, src); err != nil {
+		_ = w.Close()
+		_ = dst.Close()
+
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		_ = dst.Close()
+
+		return err
+	}
+
+	return dst.Close()
+}
+
+func compressExt(cFormat oviewer.Compressed) string {
+	switch cFormat {
+	case oviewer.GZIP:
+		return ".gz"
+	case oviewer.ZSTD:
+		return ".zst"
+	case oviewer.XZ:
+		return ".xz"
+	default:
+		return ""
+	}
+}
+
+func fileName(name string) string {
+	name = strings.TrimPrefix(name, "/")
+
+	return strings.NewReplacer("/", "_", "\\", "_", " ", "_").Replace(name)
+}
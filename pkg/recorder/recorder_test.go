@@ -0,0 +1,157 @@
+package recorder
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dimcz/viewer/pkg/logger"
+	"github.com/dimcz/viewer/pkg/oviewer"
+)
+
+func readRecords(t *testing.T, dir string) []string {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(paths)
+
+	records := make([]string, 0, len(paths))
+	for _, path := range paths {
+		fd, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var r io.Reader = fd
+		if strings.HasSuffix(path, ".gz") {
+			gr, err := gzip.NewReader(fd)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r = gr
+		}
+
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fd.Close()
+
+		records = append(records, filepath.Base(path)+": "+string(data))
+	}
+
+	return records
+}
+
+func TestRecorder_rotate(t *testing.T) {
+	dir := t.TempDir()
+
+	r, err := New(&logger.Logger{}, dir, "10B", 0, "gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := r.Attach("/web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		if _, err := io.WriteString(w, line); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r.Close()
+
+	records := readRecords(t, dir)
+	if len(records) != 3 {
+		t.Fatalf("records = %q, want 3 files", records)
+	}
+
+	if !strings.HasSuffix(records[0], ".log.gz: first\n") {
+		t.Errorf("records[0] = %q, want compressed first line", records[0])
+	}
+
+	if !strings.HasSuffix(records[1], ".log.gz: second\n") {
+		t.Errorf("records[1] = %q, want compressed second line", records[1])
+	}
+
+	if records[2] != "web.log: third\n" {
+		t.Errorf("records[2] = %q, want current file with third line", records[2])
+	}
+}
+
+func TestRecorder_reattach(t *testing.T) {
+	dir := t.TempDir()
+
+	r, err := New(&logger.Logger{}, dir, "", 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := r.Attach("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = io.WriteString(w, "2022-01-01T10:00:00Z a\n2022-01-01T10:00:01Z b\n  trace b\n")
+
+	// The next attach sends the tail again before the new lines.
+	w, err = r.Attach("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = io.WriteString(w, "2022-01-01T10:00:01Z b\n  trace b\n2022-01-01T10:00:02Z c\n")
+	_, _ = io.WriteString(w, "  trace c\n")
+
+	r.Close()
+
+	// A new session appends to the record file of the last one.
+	r, err = New(&logger.Logger{}, dir, "", 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, err = r.Attach("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = io.WriteString(w, "2022-01-01T10:00:02Z c\n2022-01-01T10:00:03Z d\n")
+
+	r.Close()
+
+	want := "web.log: 2022-01-01T10:00:00Z a\n2022-01-01T10:00:01Z b\n  trace b\n" +
+		"2022-01-01T10:00:02Z c\n  trace c\n2022-01-01T10:00:03Z d\n"
+	if records := readRecords(t, dir); len(records) != 1 || records[0] != want {
+		t.Errorf("records = %q, want %q", records, want)
+	}
+}
+
+func Test_compressFile_error(t *testing.T) {
+	// Reading a directory fails after the compressed file is created.
+	path := filepath.Join(t.TempDir(), "web.log")
+	if err := os.Mkdir(path, 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := compressFile(path, oviewer.GZIP); err == nil {
+		t.Fatal("compressFile() of a directory succeeded")
+	}
+
+	if _, err := os.Stat(path + ".gz"); !os.IsNotExist(err) {
+		t.Errorf("Stat() error = %v, want the partial archive removed", err)
+	}
+}
@@ -0,0 +1,274 @@
+package replay
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dimcz/viewer/pkg/logger"
+	"github.com/dimcz/viewer/pkg/oviewer"
+	"github.com/dimcz/viewer/pkg/source"
+	"github.com/dimcz/viewer/pkg/timestamp"
+	"github.com/pkg/errors"
+)
+
+const (
+	kind     = "replay"
+	minSpeed = 0.125
+	maxSpeed = 1024
+)
+
+var ErrNoTimestamps = errors.New("no timestamped lines to replay")
+
+type line struct {
+	offset time.Duration
+	text   string
+}
+
+// Replay plays a file of timestamped lines, such as logs saved with
+// --timestamps, with the original timing scaled by a speed factor.
+type Replay struct {
+	fileName string
+	start    time.Time
+	lines    []line
+
+	log *logger.Logger
+
+	mu     sync.Mutex
+	speed  float64
+	paused bool
+	pos    time.Duration
+	anchor time.Time
+	wake   chan struct{}
+}
+
+func New(log *logger.Logger, fileName string, speed float64) (*Replay, error) {
+	fd, err := os.Open(fileName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open replay")
+	}
+	defer fd.Close()
+
+	r := &Replay{
+		fileName: fileName,
+		log:      log,
+		speed:    clampSpeed(speed),
+		anchor:   time.Now(),
+		wake:     make(chan struct{}, 1),
+	}
+
+	if err := r.read(oviewer.UncompressedReader(fd)); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *Replay) Targets(_ context.Context) ([]source.Target, error) {
+	name := filepath.Base(r.fileName)
+
+	return []source.Target{{
+		ID:      kind + ":" + r.fileName,
+		Name:    name,
+		Caption: name + " [replay]",
+	}}, nil
+}
+
+// Open writes the lines up to the replay position and plays the rest,
+// or writes all lines at once without follow.
+func (r *Replay) Open(ctx context.Context, _ source.Target, out io.Writer, opts source.Options) error {
+	out = source.TimeFilter(out, opts)
+
+	if !opts.Follow {
+		for _, l := range r.lines {
+			if _, err := io.WriteString(out, l.text+"\n"); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	r.play(ctx, out)
+
+	return nil
+}
+
+func (r *Replay) Metadata(_ source.Target) []string {
+	return []string{
+		"replay: " + r.fileName,
+		"start: " + r.start.Format(time.RFC3339Nano),
+	}
+}
+
+func (r *Replay) Watch(_ context.Context, _ func()) error {
+	return nil
+}
+
+// TogglePause pauses or resumes the replay.
+func (r *Replay) TogglePause() {
+	r.update(func() {
+		r.paused = !r.paused
+	})
+}
+
+// SetSpeed multiplies the speed by factor.
+func (r *Replay) SetSpeed(factor float64) {
+	r.update(func() {
+		r.speed = clampSpeed(r.speed * factor)
+	})
+}
+
+// Seek moves the replay position by d and reports whether it moved back,
+// so the lines already played have to be shown again.
+func (r *Replay) Seek(d time.Duration) bool {
+	back := d < 0
+
+	r.update(func() {
+		r.pos += d
+		if r.pos < 0 {
+			r.pos = 0
+		}
+	})
+
+	return back
+}
+
+// Status describes the replay for the status line.
+func (r *Replay) Status() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := "playing"
+	if r.paused {
+		state = "paused"
+	}
+
+	return fmt.Sprintf("replay %s at %s, speed %gx",
+		state, r.start.Add(r.position()).Format("15:04:05.000"), r.speed)
+}
+
+func (r *Replay) play(ctx context.Context, out io.Writer) {
+	i := 0
+
+	for i < len(r.lines) {
+		r.mu.Lock()
+		pos, paused, speed := r.position(), r.paused, r.speed
+		r.mu.Unlock()
+
+		var b strings.Builder
+		for ; i < len(r.lines) && r.lines[i].offset <= pos; i++ {
+			b.WriteString(r.lines[i].text)
+			b.WriteByte('\n')
+		}
+
+		if b.Len() > 0 {
+			if _, err := io.WriteString(out, b.String()); err != nil {
+				r.log.Error("failed to replay: ", err)
+
+				return
+			}
+		}
+
+		if i == len(r.lines) {
+			return
+		}
+
+		var timer <-chan time.Time
+		if !paused {
+			timer = time.After(time.Duration(float64(r.lines[i].offset-pos) / speed))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+		case <-timer:
+		}
+	}
+}
+
+// update folds the elapsed time into the position before fn changes the state.
+func (r *Replay) update(fn func()) {
+	r.mu.Lock()
+	r.pos = r.position()
+	r.anchor = time.Now()
+
+	if end := r.lines[len(r.lines)-1].offset; r.pos > end {
+		r.pos = end
+	}
+
+	fn()
+	r.mu.Unlock()
+
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (r *Replay) position() time.Duration {
+	if r.paused {
+		return r.pos
+	}
+
+	return r.pos + time.Duration(float64(time.Since(r.anchor))*r.speed)
+}
+
+// read keeps lines without a timestamp with the preceding line.
+func (r *Replay) read(rd io.Reader) error {
+	scanner := bufio.NewScanner(rd)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var last time.Time
+
+	for scanner.Scan() {
+		text := scanner.Text()
+
+		if t, ok := timestamp.Line(text); ok {
+			last = t
+		}
+
+		if last.IsZero() {
+			continue
+		}
+
+		if r.start.IsZero() {
+			r.start = last
+		}
+
+		offset := last.Sub(r.start)
+		if offset < 0 {
+			offset = 0
+		}
+
+		r.lines = append(r.lines, line{offset: offset, text: text})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return errors.Wrap(err, "failed to read replay")
+	}
+
+	if len(r.lines) == 0 {
+		return ErrNoTimestamps
+	}
+
+	return nil
+}
+
+func clampSpeed(speed float64) float64 {
+	switch {
+	case speed < minSpeed:
+		return minSpeed
+	case speed > maxSpeed:
+		return maxSpeed
+	default:
+		return speed
+	}
+}
@@ -0,0 +1,110 @@
+package replay
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dimcz/viewer/pkg/logger"
+	"github.com/dimcz/viewer/pkg/source"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func testReplay(t *testing.T, speed float64) *Replay {
+	t.Helper()
+
+	fileName := filepath.Join(t.TempDir(), "replay.log")
+	content := "2022-07-01T12:00:00.000000000Z first\n" +
+		"  continued\n" +
+		"2022-07-01T12:00:00.100000000Z second\n" +
+		"2022-07-01T12:01:40.000000000Z third\n"
+	if err := os.WriteFile(fileName, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := New(&logger.Logger{}, fileName, speed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func waitFor(buf *syncBuffer, want string, d time.Duration) string {
+	deadline := time.Now().Add(d)
+	for buf.String() != want && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	return buf.String()
+}
+
+func TestReplay_play(t *testing.T) {
+	r := testReplay(t, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var buf syncBuffer
+	go func() {
+		if err := r.Open(ctx, mustTarget(t, r), &buf, source.Options{Follow: true}); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	want := "2022-07-01T12:00:00.000000000Z first\n  continued\n2022-07-01T12:00:00.100000000Z second\n"
+	if got := waitFor(&buf, want, time.Second); got != want {
+		t.Fatalf("play() = %q, want %q", got, want)
+	}
+
+	// The third line is 100s later, 10s at 10x: seek instead of waiting.
+	if r.Seek(2 * time.Minute) {
+		t.Error("Seek() forward reports back")
+	}
+	want += "2022-07-01T12:01:40.000000000Z third\n"
+	if got := waitFor(&buf, want, time.Second); got != want {
+		t.Errorf("play() after seek = %q, want %q", got, want)
+	}
+}
+
+func TestReplay_pause(t *testing.T) {
+	r := testReplay(t, 1)
+	r.TogglePause()
+	before := r.Status()
+	time.Sleep(20 * time.Millisecond)
+	if after := r.Status(); after != before {
+		t.Errorf("Status() = %q while paused, want %q", after, before)
+	}
+	if !strings.Contains(before, "paused") {
+		t.Errorf("Status() = %q, want paused", before)
+	}
+	if !r.Seek(-time.Second) {
+		t.Error("Seek() backward does not report back")
+	}
+}
+
+func mustTarget(t *testing.T, r *Replay) source.Target {
+	t.Helper()
+	targets, err := r.Targets(context.Background())
+	if err != nil || len(targets) != 1 {
+		t.Fatalf("Targets() = %v, %v", targets, err)
+	}
+	return targets[0]
+}
@@ -0,0 +1,256 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/dimcz/viewer/pkg/oviewer"
+	"github.com/pkg/errors"
+)
+
+const (
+	Version      = 1
+	manifestName = "manifest.json"
+)
+
+var ErrNoTargets = errors.New("snapshot has no containers")
+
+type Manifest struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	Targets []Target  `json:"targets"`
+}
+
+type Target struct {
+	Name     string            `json:"name"`
+	ID       string            `json:"id"`
+	Image    string            `json:"image"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Attached time.Time         `json:"attached"`
+	Detached time.Time         `json:"detached,omitempty"`
+	Marks    []int             `json:"marks,omitempty"`
+	Log      string            `json:"log"`
+	Inspect  string            `json:"inspect,omitempty"`
+}
+
+func LogName(id string) string {
+	return path.Join("containers", id+".log")
+}
+
+func InspectName(id string) string {
+	return path.Join("containers", id+".json")
+}
+
+type Writer struct {
+	name string
+	fd   *os.File
+	cw   io.WriteCloser
+	tw   *tar.Writer
+}
+
+// Create creates a snapshot archive compressed according to the extension of fileName.
+func Create(fileName string) (*Writer, error) {
+	fd, err := os.Create(fileName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create snapshot")
+	}
+
+	cw, err := oviewer.CompressedWriter(oviewer.CompressType(fileName), fd)
+	if err != nil {
+		_ = fd.Close()
+
+		return nil, errors.Wrap(err, "failed to create compressor")
+	}
+
+	return &Writer{name: fileName, fd: fd, cw: cw, tw: tar.NewWriter(cw)}, nil
+}
+
+func (w *Writer) AddFile(name, fileName string) error {
+	fd, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	fi, err := fd.Stat()
+	if err != nil {
+		return err
+	}
+
+	if err := w.header(name, fi.Size()); err != nil {
+		return err
+	}
+
+	_, err = io.CopyN(w.tw, fd, fi.Size())
+
+	return err
+}
+
+func (w *Writer) AddBytes(name string, data []byte) error {
+	if err := w.header(name, int64(len(data))); err != nil {
+		return err
+	}
+
+	_, err := w.tw.Write(data)
+
+	return err
+}
+
+// Close writes the manifest and finishes the archive.
+// The archive is removed if it cannot be finished.
+func (w *Writer) Close(m Manifest) error {
+	if err := w.finish(m); err != nil {
+		w.Abort()
+
+		return err
+	}
+
+	return nil
+}
+
+// Abort closes and removes the unfinished archive.
+func (w *Writer) Abort() {
+	_ = w.cw.Close()
+	_ = w.fd.Close()
+	_ = os.Remove(w.name)
+}
+
+func (w *Writer) finish(m Manifest) error {
+	m.Version = Version
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := w.AddBytes(manifestName, data); err != nil {
+		return err
+	}
+
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+
+	if err := w.cw.Close(); err != nil {
+		return err
+	}
+
+	return w.fd.Close()
+}
+
+func (w *Writer) header(name string, size int64) error {
+	return w.tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o640,
+		Size:    size,
+		ModTime: time.Now(),
+	})
+}
+
+type Snapshot struct {
+	Manifest
+
+	dir string
+}
+
+// Open extracts the snapshot archive into a temporary directory.
+func Open(fileName string) (*Snapshot, error) {
+	fd, err := os.Open(fileName)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open snapshot")
+	}
+	defer fd.Close()
+
+	dir, err := os.MkdirTemp(os.TempDir(), "dsnap_")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create temp dir")
+	}
+
+	s := &Snapshot{dir: dir}
+	if err := s.extract(oviewer.UncompressedReader(fd)); err != nil {
+		s.Remove()
+
+		return nil, err
+	}
+
+	if len(s.Targets) == 0 {
+		s.Remove()
+
+		return nil, ErrNoTargets
+	}
+
+	return s, nil
+}
+
+func (s *Snapshot) Path(name string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(path.Clean("/"+name)))
+}
+
+func (s *Snapshot) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(s.Path(name))
+}
+
+func (s *Snapshot) Remove() {
+	_ = os.RemoveAll(s.dir)
+}
+
+func (s *Snapshot) extract(r io.Reader) error {
+	tr := tar.NewReader(r)
+
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return errors.Wrap(err, "failed to read snapshot")
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if hdr.Name == manifestName {
+			var buf bytes.Buffer
+			if _, err := io.Copy(&buf, tr); err != nil {
+				return err
+			}
+
+			if err := json.Unmarshal(buf.Bytes(), &s.Manifest); err != nil {
+				return errors.Wrap(err, "failed to parse manifest")
+			}
+
+			continue
+		}
+
+		if err := s.extractFile(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Snapshot) extractFile(name string, r io.Reader) error {
+	p := s.Path(name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return err
+	}
+
+	fd, err := os.Create(p)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	_, err = io.Copy(fd, r)
+
+	return err
+}
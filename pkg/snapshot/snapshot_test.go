@@ -0,0 +1,111 @@
+package snapshot
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnapshot_roundTrip(t *testing.T) {
+	for _, ext := range []string{".tar", ".tar.gz", ".tar.zst"} {
+		ext := ext
+
+		t.Run(ext, func(t *testing.T) {
+			dir := t.TempDir()
+
+			logFile := filepath.Join(dir, "web.cache")
+			if err := os.WriteFile(logFile, []byte("first\nsecond\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			fileName := filepath.Join(dir, "session"+ext)
+
+			w, err := Create(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			target := Target{
+				Name:     "/web",
+				ID:       "abc",
+				Image:    "nginx",
+				Labels:   map[string]string{"app": "web"},
+				Attached: time.Date(2022, 1, 1, 10, 0, 0, 0, time.UTC),
+				Marks:    []int{1},
+				Log:      LogName("abc"),
+				Inspect:  InspectName("abc"),
+			}
+
+			if err := w.AddFile(target.Log, logFile); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := w.AddBytes(target.Inspect, []byte(`{"Id":"abc"}`)); err != nil {
+				t.Fatal(err)
+			}
+
+			created := time.Date(2022, 1, 1, 11, 0, 0, 0, time.UTC)
+			if err := w.Close(Manifest{Created: created, Targets: []Target{target}}); err != nil {
+				t.Fatal(err)
+			}
+
+			s, err := Open(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer s.Remove()
+
+			want := Manifest{Version: Version, Created: created, Targets: []Target{target}}
+			if !reflect.DeepEqual(s.Manifest, want) {
+				t.Errorf("Manifest = %+v, want %+v", s.Manifest, want)
+			}
+
+			if data, err := s.ReadFile(target.Log); err != nil || string(data) != "first\nsecond\n" {
+				t.Errorf("ReadFile(log) = %q, %v", data, err)
+			}
+
+			if data, err := s.ReadFile(target.Inspect); err != nil || string(data) != `{"Id":"abc"}` {
+				t.Errorf("ReadFile(inspect) = %q, %v", data, err)
+			}
+		})
+	}
+}
+
+func TestOpen_noTargets(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "empty.tar")
+
+	w, err := Create(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.Close(Manifest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(fileName); !errors.Is(err, ErrNoTargets) {
+		t.Errorf("Open() error = %v, want %v", err, ErrNoTargets)
+	}
+}
+
+func TestWriter_Abort(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "session.tar.gz")
+
+	w, err := Create(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.AddFile(LogName("abc"), filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Fatal("AddFile() of a missing file succeeded")
+	}
+
+	w.Abort()
+
+	if _, err := os.Stat(fileName); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Stat() error = %v, want the archive removed", err)
+	}
+}
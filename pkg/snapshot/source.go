@@ -0,0 +1,97 @@
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dimcz/viewer/pkg/logger"
+	"github.com/dimcz/viewer/pkg/source"
+)
+
+// Source plays the containers of a snapshot like the docker daemon does.
+type Source struct {
+	snap *Snapshot
+
+	log *logger.Logger
+}
+
+func NewSource(log *logger.Logger, fileName string) (*Source, error) {
+	snap, err := Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Source{snap: snap, log: log}, nil
+}
+
+func (s *Source) Targets(_ context.Context) ([]source.Target, error) {
+	targets := make([]source.Target, 0, len(s.snap.Targets))
+
+	for _, t := range s.snap.Targets {
+		targets = append(targets, source.Target{
+			ID:     t.ID,
+			Name:   t.Name,
+			Image:  t.Image,
+			Labels: t.Labels,
+			Caption: fmt.Sprintf("%s (ID:%s) [snapshot %s]",
+				t.Name,
+				source.ShortID(t.ID),
+				s.snap.Created.Format("2006-01-02 15:04:05")),
+		})
+	}
+
+	return targets, nil
+}
+
+func (s *Source) Open(_ context.Context, t source.Target, out io.Writer, opts source.Options) error {
+	fd, err := os.Open(s.snap.Path(s.target(t).Log))
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	_, err = io.Copy(source.TimeFilter(out, opts), fd)
+
+	return err
+}
+
+func (s *Source) Metadata(t source.Target) []string {
+	return []string{
+		"container: " + t.Name,
+		"id: " + t.ID,
+		"image: " + t.Image,
+		"snapshot: " + s.snap.Created.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+func (s *Source) Watch(_ context.Context, _ func()) error {
+	return nil
+}
+
+func (s *Source) Marks(t source.Target) []int {
+	return s.target(t).Marks
+}
+
+func (s *Source) Inspect(_ context.Context, t source.Target) ([]byte, error) {
+	if name := s.target(t).Inspect; name != "" {
+		return s.snap.ReadFile(name)
+	}
+
+	return nil, os.ErrNotExist
+}
+
+func (s *Source) Close() {
+	s.snap.Remove()
+}
+
+func (s *Source) target(t source.Target) Target {
+	for _, st := range s.snap.Targets {
+		if st.ID == t.ID {
+			return st
+		}
+	}
+
+	return Target{}
+}
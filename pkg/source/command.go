@@ -0,0 +1,60 @@
+package source
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/dimcz/viewer/pkg/logger"
+)
+
+const defaultShell = "/bin/sh"
+
+// Commands shows the output of shell commands, e.g. `journalctl -f`.
+// Every open runs the command again until it exits.
+type Commands struct {
+	commands []string
+	log      *logger.Logger
+}
+
+func NewCommands(log *logger.Logger, commands []string) *Commands {
+	return &Commands{commands: commands, log: log}
+}
+
+func (c *Commands) Targets(_ context.Context) ([]Target, error) {
+	targets := make([]Target, 0, len(c.commands))
+
+	for _, cmd := range c.commands {
+		targets = append(targets, Target{ID: "command:" + cmd, Name: cmd, Caption: "$ " + cmd})
+	}
+
+	return targets, nil
+}
+
+func (c *Commands) Open(ctx context.Context, t Target, out io.Writer, opts Options) error {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = defaultShell
+	}
+
+	out = TimeFilter(out, opts)
+
+	cmd := exec.CommandContext(ctx, shell, "-c", t.Name)
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	if err := cmd.Run(); err != nil && ctx.Err() == nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Commands) Metadata(t Target) []string {
+	return []string{"command: " + t.Name}
+}
+
+func (c *Commands) Watch(_ context.Context, _ func()) error {
+	return nil
+}
@@ -0,0 +1,228 @@
+package source
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/dimcz/viewer/pkg/logger"
+)
+
+const (
+	pollInterval  = 500 * time.Millisecond
+	watchInterval = 2 * time.Second
+	blockSize     = 4096
+)
+
+// Files follows local log files matched by glob patterns.
+type Files struct {
+	patterns []string
+	log      *logger.Logger
+}
+
+func NewFiles(log *logger.Logger, patterns []string) *Files {
+	return &Files{patterns: patterns, log: log}
+}
+
+func (f *Files) Targets(_ context.Context) ([]Target, error) {
+	seen := make(map[string]bool)
+
+	var targets []Target
+
+	for _, pattern := range f.patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		sort.Strings(matches)
+
+		for _, m := range matches {
+			path, err := filepath.Abs(m)
+			if err != nil || seen[path] {
+				continue
+			}
+
+			if fi, err := os.Stat(path); err != nil || fi.IsDir() {
+				continue
+			}
+
+			seen[path] = true
+
+			targets = append(targets, Target{ID: path, Name: m, Caption: m})
+		}
+	}
+
+	return targets, nil
+}
+
+func (f *Files) Open(ctx context.Context, t Target, out io.Writer, opts Options) error {
+	fd, err := os.Open(t.ID)
+	if err != nil {
+		return err
+	}
+
+	offset, err := tailOffset(fd, opts.Tail)
+	if err == nil {
+		_, err = fd.Seek(offset, io.SeekStart)
+	}
+
+	if err != nil {
+		_ = fd.Close()
+
+		return err
+	}
+
+	out = TimeFilter(out, opts)
+
+	if !opts.Follow {
+		defer fd.Close()
+
+		_, err := io.Copy(out, fd)
+
+		return err
+	}
+
+	f.follow(ctx, t.ID, fd, offset, out)
+
+	return nil
+}
+
+func (f *Files) Metadata(t Target) []string {
+	return []string{"file: " + t.ID}
+}
+
+func (f *Files) Watch(ctx context.Context, changed func()) error {
+	last, err := f.Targets(ctx)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		targets, err := f.Targets(ctx)
+		if err != nil {
+			return err
+		}
+
+		if !sameTargets(last, targets) {
+			last = targets
+
+			changed()
+		}
+	}
+}
+
+// follow copies new data of the file into out and starts over
+// when the file is truncated or replaced by rotation.
+func (f *Files) follow(ctx context.Context, path string, fd *os.File, offset int64, out io.Writer) {
+	defer func() {
+		f.log.LogOnErr(fd.Close())
+	}()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := io.Copy(out, fd)
+		if err != nil {
+			f.log.Error("failed to read ", path, ": ", err)
+
+			return
+		}
+
+		offset += n
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		fi, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		cur, err := fd.Stat()
+		if err == nil && os.SameFile(fi, cur) && fi.Size() >= offset {
+			continue
+		}
+
+		next, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+
+		f.log.LogOnErr(fd.Close())
+		fd, offset = next, 0
+	}
+}
+
+// tailOffset returns the offset of the last tail lines of the file.
+func tailOffset(fd *os.File, tail int) (int64, error) {
+	if tail <= 0 {
+		return 0, nil
+	}
+
+	fi, err := fd.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	size := fi.Size()
+	buf := make([]byte, blockSize)
+	lines := 0
+
+	for pos := size; pos > 0; {
+		n := int64(len(buf))
+		if pos < n {
+			n = pos
+		}
+
+		pos -= n
+
+		if _, err := fd.ReadAt(buf[:n], pos); err != nil && err != io.EOF {
+			return 0, err
+		}
+
+		for i := n - 1; i >= 0; i-- {
+			// The newline that ends the last line does not start a new one.
+			if buf[i] != '\n' || pos+i == size-1 {
+				continue
+			}
+
+			lines++
+			if lines == tail {
+				return pos + i + 1, nil
+			}
+		}
+	}
+
+	return 0, nil
+}
+
+func sameTargets(a, b []Target) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i].ID != b[i].ID {
+			return false
+		}
+	}
+
+	return true
+}
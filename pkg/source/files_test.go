@@ -0,0 +1,85 @@
+package source
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_tailOffset(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		tail    int
+		want    int64
+	}{
+		{
+			name:    "all",
+			content: "a\nb\nc\n",
+			tail:    0,
+			want:    0,
+		},
+		{
+			name:    "last two",
+			content: "a\nb\nc\n",
+			tail:    2,
+			want:    2,
+		},
+		{
+			name:    "no trailing newline",
+			content: "a\nb\nc",
+			tail:    1,
+			want:    4,
+		},
+		{
+			name:    "more than lines",
+			content: "a\nb\n",
+			tail:    10,
+			want:    0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := filepath.Join(t.TempDir(), "test.log")
+			if err := os.WriteFile(fileName, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			fd, err := os.Open(fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer fd.Close()
+
+			got, err := tailOffset(fd, tt.tail)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("tailOffset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFiles_Targets(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"b.log", "a.log", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f := NewFiles(nil, []string{filepath.Join(dir, "*.log"), filepath.Join(dir, "a.log")})
+
+	targets, err := f.Targets(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("Targets() = %v, want 2 targets", targets)
+	}
+	if filepath.Base(targets[0].ID) != "a.log" || filepath.Base(targets[1].ID) != "b.log" {
+		t.Errorf("Targets() = %v, want a.log, b.log", targets)
+	}
+}
@@ -0,0 +1,60 @@
+package source
+
+import (
+	"bytes"
+	"io"
+	"time"
+
+	"github.com/dimcz/viewer/pkg/timestamp"
+)
+
+type timeFilter struct {
+	out   io.Writer
+	since time.Time
+	until time.Time
+
+	buf  []byte
+	keep bool
+}
+
+// TimeFilter drops the lines whose leading timestamp is out of the range of opts.
+// Lines without a timestamp go with the preceding line.
+func TimeFilter(out io.Writer, opts Options) io.Writer {
+	if opts.Since.IsZero() && opts.Until.IsZero() {
+		return out
+	}
+
+	return &timeFilter{out: out, since: opts.Since, until: opts.Until, keep: true}
+}
+
+func (f *timeFilter) Write(p []byte) (int, error) {
+	f.buf = append(f.buf, p...)
+
+	var kept bytes.Buffer
+
+	for {
+		i := bytes.IndexByte(f.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		line := f.buf[:i+1]
+		if t, ok := timestamp.Line(string(line)); ok {
+			f.keep = (f.since.IsZero() || !t.Before(f.since)) && (f.until.IsZero() || t.Before(f.until))
+		}
+
+		if f.keep {
+			kept.Write(line)
+		}
+
+		f.buf = f.buf[i+1:]
+	}
+
+	if kept.Len() > 0 {
+		if _, err := f.out.Write(kept.Bytes()); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,324 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/dimcz/viewer/pkg/logger"
+)
+
+const loadDelay = 100 * time.Millisecond
+
+type entry struct {
+	Target
+
+	src   LogSource
+	owner int
+}
+
+// Navigator joins the targets of several sources into one list
+// walked with next and prev.
+type Navigator struct {
+	sources []LogSource
+	log     *logger.Logger
+
+	mu      sync.Mutex
+	targets [][]Target
+	list    []entry
+	current entry
+
+	cancel func()
+}
+
+func NewNavigator(log *logger.Logger, sources ...LogSource) (*Navigator, error) {
+	n := &Navigator{
+		sources: sources,
+		log:     log,
+		targets: make([][]Target, len(sources)),
+	}
+
+	for i, s := range sources {
+		targets, err := s.Targets(context.Background())
+		if err != nil {
+			return nil, err
+		}
+
+		n.targets[i] = targets
+	}
+
+	n.rebuild()
+
+	if len(n.list) > 0 {
+		n.current = n.list[0]
+	}
+
+	return n, nil
+}
+
+// Watch refreshes the targets of every source on changes until ctx is done.
+// appeared is called when the first target appears in an empty navigator.
+func (n *Navigator) Watch(ctx context.Context, appeared func()) {
+	for i, s := range n.sources {
+		i, s := i, s
+
+		go func() {
+			err := s.Watch(ctx, func() {
+				if n.refresh(ctx, i) {
+					appeared()
+				}
+			})
+			if err != nil && ctx.Err() == nil {
+				n.log.Error("failed to watch source: ", err)
+			}
+		}()
+	}
+}
+
+// Load follows the current target into out until Stop and gives
+// the stream a moment to write the first lines.
+func (n *Navigator) Load(ctx context.Context, out io.Writer, tail int) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	n.mu.Lock()
+	n.cancel = cancel
+	n.mu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		n.open(ctx, out, Options{Tail: tail, Follow: true})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(loadDelay):
+	}
+}
+
+// Targets returns the targets of all sources.
+func (n *Navigator) Targets() []Target {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	targets := make([]Target, 0, len(n.list))
+	for _, e := range n.list {
+		targets = append(targets, e.Target)
+	}
+
+	return targets
+}
+
+// OpenTarget streams any known target like LogSource.Open does.
+func (n *Navigator) OpenTarget(ctx context.Context, t Target, out io.Writer, opts Options) error {
+	n.mu.Lock()
+
+	var src LogSource
+
+	for _, e := range n.list {
+		if e.ID == t.ID {
+			src = e.src
+
+			break
+		}
+	}
+	n.mu.Unlock()
+
+	if src == nil {
+		return ErrNoTarget
+	}
+
+	return src.Open(ctx, t, out, opts)
+}
+
+func (n *Navigator) Stop() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.cancel != nil {
+		n.cancel()
+		n.cancel = nil
+	}
+}
+
+// Select makes the target with the id current and reports whether it is known.
+func (n *Navigator) Select(id string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for _, e := range n.list {
+		if e.ID == id {
+			n.current = e
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func (n *Navigator) SetNext() {
+	n.move(1)
+}
+
+func (n *Navigator) SetPrev() {
+	n.move(-1)
+}
+
+func (n *Navigator) Name() string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.current.src == nil {
+		return "(0/0) no targets"
+	}
+
+	pos := "?"
+	if i := n.index(); i >= 0 {
+		pos = fmt.Sprint(i + 1)
+	}
+
+	return fmt.Sprintf("(%s/%d) %s", pos, len(n.list), n.current.Caption)
+}
+
+func (n *Navigator) Current() Target {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.current.Target
+}
+
+// Source returns the source of the current target.
+func (n *Navigator) Source() LogSource {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.current.src
+}
+
+func (n *Navigator) Metadata() []string {
+	c := n.currentEntry()
+	if c.src == nil {
+		return nil
+	}
+
+	return c.src.Metadata(c.Target)
+}
+
+// Marks returns the marks restored by the source of the current target.
+func (n *Navigator) Marks() ([]int, bool) {
+	c := n.currentEntry()
+
+	mk, ok := c.src.(Marker)
+	if !ok {
+		return nil, false
+	}
+
+	return mk.Marks(c.Target), true
+}
+
+// Inspect returns raw details of t, which may be any known target.
+func (n *Navigator) Inspect(ctx context.Context, t Target) ([]byte, error) {
+	n.mu.Lock()
+	src := n.current.src
+	for _, e := range n.list {
+		if e.ID == t.ID {
+			src = e.src
+
+			break
+		}
+	}
+	n.mu.Unlock()
+
+	in, ok := src.(Inspector)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+
+	return in.Inspect(ctx, t)
+}
+
+func (n *Navigator) open(ctx context.Context, out io.Writer, opts Options) {
+	c := n.currentEntry()
+	if c.src == nil {
+		return
+	}
+
+	if err := c.src.Open(ctx, c.Target, out, opts); err != nil && ctx.Err() == nil {
+		n.log.Error("failed to open ", c.Name, ": ", err)
+	}
+}
+
+func (n *Navigator) currentEntry() entry {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.current
+}
+
+func (n *Navigator) move(step int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if len(n.list) == 0 {
+		return
+	}
+
+	// A gone target steps to the first or the last one.
+	i := n.index()
+	if i < 0 && step < 0 {
+		i = 0
+	}
+
+	n.current = n.list[(i+step+len(n.list))%len(n.list)]
+}
+
+// refresh lists the targets of the source again and reports
+// whether the navigator got its first target.
+func (n *Navigator) refresh(ctx context.Context, i int) bool {
+	targets, err := n.sources[i].Targets(ctx)
+	if err != nil {
+		n.log.Error("failed to refresh targets: ", err)
+
+		return false
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.targets[i] = targets
+	n.rebuild()
+
+	if n.current.src == nil && len(n.list) > 0 {
+		n.current = n.list[0]
+
+		return true
+	}
+
+	return false
+}
+
+func (n *Navigator) rebuild() {
+	n.list = n.list[:0]
+
+	for i, targets := range n.targets {
+		for _, t := range targets {
+			n.list = append(n.list, entry{Target: t, src: n.sources[i], owner: i})
+		}
+	}
+}
+
+// index returns the position of the current target, or -1 when it is gone.
+func (n *Navigator) index() int {
+	for i, e := range n.list {
+		if e.ID == n.current.ID && e.owner == n.current.owner {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,47 @@
+package source
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/dimcz/viewer/pkg/logger"
+)
+
+type testSource []Target
+
+func (s testSource) Targets(_ context.Context) ([]Target, error) { return s, nil }
+
+func (s testSource) Open(_ context.Context, t Target, out io.Writer, _ Options) error {
+	_, err := io.WriteString(out, t.Name+"\n")
+
+	return err
+}
+
+func (s testSource) Metadata(t Target) []string { return []string{t.Name} }
+
+func (s testSource) Watch(_ context.Context, _ func()) error { return nil }
+
+func TestNavigator_mixed(t *testing.T) {
+	nav, err := NewNavigator(&logger.Logger{},
+		testSource{{ID: "c1", Name: "container", Caption: "container"}},
+		testSource{{ID: "f1", Name: "file1", Caption: "file1"}, {ID: "f2", Name: "file2", Caption: "file2"}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"file1", "file2", "container", "file1"}
+	for _, w := range want {
+		nav.SetNext()
+		if got := nav.Current().Name; got != w {
+			t.Errorf("SetNext() = %v, want %v", got, w)
+		}
+	}
+
+	nav.SetPrev()
+	nav.SetPrev()
+	if got := nav.Name(); got != "(3/3) file2" {
+		t.Errorf("Name() = %v, want (3/3) file2", got)
+	}
+}
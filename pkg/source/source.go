@@ -0,0 +1,68 @@
+package source
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNotSupported = errors.New("not supported by the source")
+	ErrNoTarget     = errors.New("target not found")
+)
+
+// Target is a single log stream of a source, e.g. a container or a file.
+type Target struct {
+	ID      string
+	Name    string
+	Image   string
+	Caption string
+	Labels  map[string]string
+}
+
+// LogSource provides log targets to the viewer.
+type LogSource interface {
+	// Targets lists the current targets of the source.
+	Targets(ctx context.Context) ([]Target, error)
+	// Open streams the logs of t selected by opts into out and returns
+	// when the stream ends or ctx is done.
+	Open(ctx context.Context, t Target, out io.Writer, opts Options) error
+	// Metadata describes t in the header of saved documents.
+	Metadata(t Target) []string
+	// Watch calls changed each time the targets may have changed, which may
+	// be many times, and returns nil when ctx is done or the error that ended
+	// the watch. Sources whose targets never change return nil at once
+	// without calling changed.
+	Watch(ctx context.Context, changed func()) error
+}
+
+// Options select the logs of a target.
+type Options struct {
+	// Tail is the number of last lines, all lines when 0.
+	Tail int
+	// Follow keeps streaming new logs.
+	Follow bool
+	// Since and Until limit the logs to a time range when set.
+	Since time.Time
+	Until time.Time
+}
+
+// Inspector is implemented by sources that provide raw details of a target.
+type Inspector interface {
+	Inspect(ctx context.Context, t Target) ([]byte, error)
+}
+
+// Marker is implemented by sources that restore the marks of a target.
+type Marker interface {
+	Marks(t Target) []int
+}
+
+func ShortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+
+	return id
+}
@@ -0,0 +1,209 @@
+package source
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// TimeLayout is the timestamp of formatted entries.
+	TimeLayout   = "2006-01-02T15:04:05.000000Z07:00"
+	SeverityInfo = 6
+)
+
+var severities = []string{"EMERG", "ALERT", "CRIT", "ERROR", "WARN", "NOTICE", "INFO", "DEBUG"}
+
+// Streams keeps lines split into named streams in memory and serves them
+// as targets. It is the base of sources that demultiplex one input,
+// e.g. the journal by unit.
+type Streams struct {
+	kind  string
+	limit int
+
+	mu       sync.Mutex
+	streams  map[string]*stream
+	watchers map[int]func()
+	nextID   int
+}
+
+type stream struct {
+	lines []string
+	subs  map[int]subscriber
+}
+
+// subscriber is an open target following a stream until its ctx is done.
+type subscriber struct {
+	ctx context.Context
+	out io.Writer
+}
+
+// NewStreams returns streams whose target IDs are prefixed with kind.
+// Every stream keeps the last limit lines.
+func NewStreams(kind string, limit int) *Streams {
+	return &Streams{
+		kind:     kind,
+		limit:    limit,
+		streams:  make(map[string]*stream),
+		watchers: make(map[int]func()),
+	}
+}
+
+// Append adds lines to the named stream and sends them to its open targets.
+func (s *Streams) Append(name string, lines ...string) {
+	s.mu.Lock()
+
+	st, ok := s.streams[name]
+	if !ok {
+		st = &stream{subs: make(map[int]subscriber)}
+		s.streams[name] = st
+	}
+
+	st.lines = append(st.lines, lines...)
+	if over := len(st.lines) - s.limit; s.limit > 0 && over > 0 {
+		st.lines = append(st.lines[:0], st.lines[over:]...)
+	}
+
+	// A subscriber is removed as soon as its ctx is done, so nothing is
+	// written after the caller stopped it and closed out.
+	data := strings.Join(lines, "\n") + "\n"
+	for id, sub := range st.subs {
+		if sub.ctx.Err() != nil {
+			delete(st.subs, id)
+
+			continue
+		}
+
+		_, _ = io.WriteString(sub.out, data)
+	}
+
+	var watchers []func()
+	if !ok {
+		for _, fn := range s.watchers {
+			watchers = append(watchers, fn)
+		}
+	}
+
+	s.mu.Unlock()
+
+	for _, fn := range watchers {
+		fn()
+	}
+}
+
+func (s *Streams) Targets(_ context.Context) ([]Target, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.streams))
+	for name := range s.streams {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	targets := make([]Target, 0, len(names))
+	for _, name := range names {
+		targets = append(targets, Target{ID: s.kind + ":" + name, Name: name, Caption: name})
+	}
+
+	return targets, nil
+}
+
+func (s *Streams) Open(ctx context.Context, t Target, out io.Writer, opts Options) error {
+	s.mu.Lock()
+
+	st, ok := s.streams[t.Name]
+	if !ok {
+		s.mu.Unlock()
+
+		return ErrNoTarget
+	}
+
+	out = TimeFilter(out, opts)
+
+	lines := st.lines
+	if opts.Tail > 0 && len(lines) > opts.Tail {
+		lines = lines[len(lines)-opts.Tail:]
+	}
+
+	if len(lines) > 0 {
+		if _, err := io.WriteString(out, strings.Join(lines, "\n")+"\n"); err != nil {
+			s.mu.Unlock()
+
+			return err
+		}
+	}
+
+	if !opts.Follow {
+		s.mu.Unlock()
+
+		return nil
+	}
+
+	id := s.id()
+	st.subs[id] = subscriber{ctx: ctx, out: out}
+	s.mu.Unlock()
+
+	<-ctx.Done()
+
+	s.mu.Lock()
+	delete(st.subs, id)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// Watch reports new streams.
+func (s *Streams) Watch(ctx context.Context, changed func()) error {
+	s.mu.Lock()
+	id := s.id()
+	s.watchers[id] = changed
+	s.mu.Unlock()
+
+	<-ctx.Done()
+
+	s.mu.Lock()
+	delete(s.watchers, id)
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *Streams) id() int {
+	s.nextID++
+
+	return s.nextID
+}
+
+// SeverityName returns the level name of a syslog severity.
+func SeverityName(severity int) string {
+	if severity < 0 || severity >= len(severities) {
+		return severities[SeverityInfo]
+	}
+
+	return severities[severity]
+}
+
+// EntryLines formats a log entry of the streams; continuation lines
+// of a multi-line message are indented under the first one.
+func EntryLines(t time.Time, severity int, msg string) []string {
+	prefix := t.Format(TimeLayout) + " " + SeverityName(severity) + " "
+	indent := strings.Repeat(" ", len(prefix))
+
+	msgLines := strings.Split(strings.TrimRight(msg, "\n"), "\n")
+	lines := make([]string, len(msgLines))
+
+	for i, m := range msgLines {
+		if i == 0 {
+			lines[i] = prefix + m
+		} else {
+			lines[i] = indent + m
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,69 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func subscribed(s *Streams, name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.streams[name].subs) > 0
+}
+
+func TestStreams_OpenStop(t *testing.T) {
+	s := NewStreams("syslog", 100)
+	s.Append("host", "first")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &syncBuffer{}
+	done := make(chan error, 1)
+
+	go func() {
+		done <- s.Open(ctx, Target{Name: "host"}, out, Options{Follow: true})
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for !subscribed(s, "host") {
+		if time.Now().After(deadline) {
+			t.Fatal("Open() did not subscribe")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	s.Append("host", "second")
+	cancel()
+	s.Append("host", "third")
+
+	if got := out.String(); got != "first\nsecond\n" {
+		t.Errorf("Open() output = %q after stop", got)
+	}
+
+	if err := <-done; err != nil {
+		t.Errorf("Open() error = %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+// Package timestamp detects the timestamps of log lines, so the viewer,
+// the replay and the time filters agree on the time of a line.
+package timestamp
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// layout is a timestamp layout and the expression that finds it in a line.
+type layout struct {
+	reg     *regexp.Regexp
+	layouts []string
+	// noYear is a layout without a year, which takes the current year.
+	noYear bool
+}
+
+// layouts are the timestamp layouts detected, the first found is used.
+var layouts = []layout{
+	{
+		// RFC3339 and ISO 8601 (docker --timestamps, zap, logrus, Python logging).
+		reg: regexp.MustCompile(`\d{4}-\d\d-\d\d[T ]\d\d:\d\d:\d\d(?:[.,]\d+)?(?:Z|[+-]\d\d:?\d\d)?`),
+		layouts: []string{
+			time.RFC3339Nano,
+			"2006-01-02T15:04:05.999999999-0700",
+			"2006-01-02T15:04:05.999999999",
+		},
+	},
+	{
+		// Go log package.
+		reg:     regexp.MustCompile(`\d{4}/\d\d/\d\d \d\d:\d\d:\d\d(?:\.\d+)?`),
+		layouts: []string{"2006/01/02 15:04:05.999999999"},
+	},
+	{
+		// nginx and Apache access log.
+		reg:     regexp.MustCompile(`\d\d/[A-Z][a-z]{2}/\d{4}:\d\d:\d\d:\d\d [+-]\d{4}`),
+		layouts: []string{"02/Jan/2006:15:04:05 -0700"},
+	},
+	{
+		// klog and glog.
+		reg:     regexp.MustCompile(`^[IWEF]\d{4} \d\d:\d\d:\d\d(?:\.\d+)?`),
+		layouts: []string{"I0102 15:04:05.999999999"},
+		noYear:  true,
+	},
+	{
+		// syslog.
+		reg:     regexp.MustCompile(`\b[A-Z][a-z]{2} [ \d]\d \d\d:\d\d:\d\d\b`),
+		layouts: []string{time.Stamp},
+		noYear:  true,
+	},
+}
+
+var (
+	// epochReg matches the epoch time field of JSON logs (zap, pino, bunyan).
+	epochReg = regexp.MustCompile(`"(?:time|ts|timestamp)"\s*:\s*(\d+(?:\.\d+)?)`)
+	// escapeReg matches the escape sequences of colored lines.
+	escapeReg = regexp.MustCompile("\x1b\\[[\\d;*]*m")
+)
+
+// Line returns the timestamp of a log line, the first of the layouts found.
+// Timestamps without a zone are in the local time.
+func Line(line string) (time.Time, bool) {
+	return Find(line, time.Now())
+}
+
+// Find returns the first timestamp of the layouts found in the line,
+// a timestamp without a year is in the year of now.
+func Find(line string, now time.Time) (time.Time, bool) {
+	line = strip(line)
+
+	for _, l := range layouts {
+		if s := l.reg.FindString(line); s != "" {
+			if t, ok := l.parse(s, now); ok {
+				return t, true
+			}
+		}
+	}
+
+	if m := epochReg.FindStringSubmatch(line); m != nil {
+		return Epoch(m[1])
+	}
+
+	return time.Time{}, false
+}
+
+// Leading returns the timestamp the line starts with and its length.
+func Leading(line string, now time.Time) (time.Time, int, bool) {
+	for _, l := range layouts {
+		loc := l.reg.FindStringIndex(line)
+		if loc == nil || loc[0] != 0 {
+			continue
+		}
+
+		if t, ok := l.parse(line[:loc[1]], now); ok {
+			return t, loc[1], true
+		}
+	}
+
+	return time.Time{}, 0, false
+}
+
+// Epoch returns the time of a number of seconds, as zap writes,
+// or of milliseconds, as pino and bunyan write.
+func Epoch(s string) (time.Time, bool) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f <= 0 {
+		return time.Time{}, false
+	}
+
+	if f > 1e12 {
+		f /= 1000
+	}
+
+	sec, frac := math.Modf(f)
+
+	return time.Unix(int64(sec), int64(frac*1e9)), true
+}
+
+// parse parses the timestamp found by the expression of the layout.
+func (l layout) parse(s string, now time.Time) (time.Time, bool) {
+	s = normalize(s, l.noYear)
+
+	for _, layout := range l.layouts {
+		t, err := time.ParseInLocation(layout, s, time.Local)
+		if err != nil {
+			continue
+		}
+
+		if l.noYear {
+			t = withYear(t, now)
+		}
+
+		return t, true
+	}
+
+	return time.Time{}, false
+}
+
+// normalize rewrites the separators the layouts do not accept.
+func normalize(s string, noYear bool) string {
+	if noYear {
+		// The level letter of klog.
+		if s[0] >= 'E' && s[0] <= 'W' && s[1] >= '0' && s[1] <= '9' {
+			s = "I" + s[1:]
+		}
+
+		return s
+	}
+
+	if len(s) > 10 && s[10] == ' ' && s[4] == '-' {
+		s = s[:10] + "T" + s[11:]
+	}
+
+	return strings.Replace(s, ",", ".", 1)
+}
+
+// withYear sets the year of a timestamp without a year,
+// the last year if it would be in the future.
+func withYear(t time.Time, now time.Time) time.Time {
+	t = t.AddDate(now.Year()-t.Year(), 0, 0)
+	if t.After(now.AddDate(0, 0, 1)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+
+	return t
+}
+
+func strip(line string) string {
+	if strings.IndexByte(line, '\x1b') < 0 {
+		return line
+	}
+
+	return escapeReg.ReplaceAllString(line, "")
+}
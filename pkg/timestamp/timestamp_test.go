@@ -0,0 +1,103 @@
+package timestamp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFind(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		name   string
+		line   string
+		want   time.Time
+		wantOk bool
+	}{
+		{
+			name:   "docker",
+			line:   "2026-10-18T14:05:00.5Z hello",
+			want:   time.Date(2026, 10, 18, 14, 5, 0, 500000000, time.UTC),
+			wantOk: true,
+		},
+		{
+			name:   "python",
+			line:   "2026-10-18 14:05:00,123 INFO hello",
+			want:   time.Date(2026, 10, 18, 14, 5, 0, 123000000, time.Local),
+			wantOk: true,
+		},
+		{
+			name:   "go log",
+			line:   "2026/10/18 14:05:00 hello",
+			want:   time.Date(2026, 10, 18, 14, 5, 0, 0, time.Local),
+			wantOk: true,
+		},
+		{
+			name:   "access log",
+			line:   `10.0.0.1 - - [18/Oct/2026:14:05:00 +0000] "GET / HTTP/1.1" 200 1`,
+			want:   time.Date(2026, 10, 18, 14, 5, 0, 0, time.UTC),
+			wantOk: true,
+		},
+		{
+			name:   "klog",
+			line:   "E1018 14:05:00.000100 1 main.go:1] failed",
+			want:   time.Date(2026, 10, 18, 14, 5, 0, 100000, time.Local),
+			wantOk: true,
+		},
+		{
+			name:   "syslog last year",
+			line:   "Dec 31 23:59:59 host sshd[1]: closed",
+			want:   time.Date(2025, 12, 31, 23, 59, 59, 0, time.Local),
+			wantOk: true,
+		},
+		{
+			name:   "json epoch",
+			line:   `{"level":"info","ts":1760796300,"msg":"hello"}`,
+			want:   time.Unix(1760796300, 0),
+			wantOk: true,
+		},
+		{
+			name:   "colored",
+			line:   "\x1b[32m2026-10-18T14:05:00Z\x1b[0m hello",
+			want:   time.Date(2026, 10, 18, 14, 5, 0, 0, time.UTC),
+			wantOk: true,
+		},
+		{
+			name:   "none",
+			line:   "    at main.go:10",
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Find(tt.line, now)
+			if ok != tt.wantOk {
+				t.Fatalf("Find() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Find() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeading(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		name   string
+		line   string
+		wantN  int
+		wantOk bool
+	}{
+		{name: "docker", line: "2026-10-18T14:05:00.5Z hello", wantN: 22, wantOk: true},
+		{name: "klog", line: "I1018 14:05:00.000100 1 main.go:1] ok", wantN: 21, wantOk: true},
+		{name: "not leading", line: "hello 2026-10-18T14:05:00Z", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, n, ok := Leading(tt.line, now)
+			if ok != tt.wantOk || n != tt.wantN {
+				t.Errorf("Leading() = %d, %v, want %d, %v", n, ok, tt.wantN, tt.wantOk)
+			}
+		})
+	}
+}